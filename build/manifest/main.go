@@ -1,6 +1,7 @@
 package main
 
 import (
+	"bytes"
 	"encoding/json"
 	"fmt"
 	"io/ioutil"
@@ -87,16 +88,19 @@ func findManifest() (*model.Manifest, error) {
 	if err != nil {
 		return nil, errors.Wrap(err, "failed to find manifest in current working directory")
 	}
-	manifestFile, err := os.Open(manifestFilePath)
+	manifestData, err := ioutil.ReadFile(manifestFilePath)
 	if err != nil {
 		return nil, errors.Wrapf(err, "failed to open %s", manifestFilePath)
 	}
-	defer manifestFile.Close()
+	manifestData, err = withoutServerOnlyFields(manifestData)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to parse manifest")
+	}
 
 	// Re-decode the manifest, disallowing unknown fields. When we write the manifest back out,
 	// we don't want to accidentally clobber anything we won't preserve.
 	var manifest model.Manifest
-	decoder := json.NewDecoder(manifestFile)
+	decoder := json.NewDecoder(bytes.NewReader(manifestData))
 	decoder.DisallowUnknownFields()
 	if err = decoder.Decode(&manifest); err != nil {
 		return nil, errors.Wrap(err, "failed to parse manifest")
@@ -105,6 +109,49 @@ func findManifest() (*model.Manifest, error) {
 	return &manifest, nil
 }
 
+// serverOnlySettingFields are setting fields read by the server from plugin.json which model.PluginSetting
+// doesn't have yet. They aren't needed by the generated manifests, so they are left out when decoding.
+var serverOnlySettingFields = []string{"secret"}
+
+// withoutServerOnlyFields returns the manifest without serverOnlySettingFields.
+func withoutServerOnlyFields(data []byte) ([]byte, error) {
+	var raw map[string]json.RawMessage
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return nil, err
+	}
+	schemaData, ok := raw["settings_schema"]
+	if !ok {
+		return data, nil
+	}
+
+	var schema map[string]json.RawMessage
+	if err := json.Unmarshal(schemaData, &schema); err != nil {
+		return nil, err
+	}
+	settingsData, ok := schema["settings"]
+	if !ok {
+		return data, nil
+	}
+	var settings []map[string]json.RawMessage
+	if err := json.Unmarshal(settingsData, &settings); err != nil {
+		return nil, err
+	}
+	for _, setting := range settings {
+		for _, field := range serverOnlySettingFields {
+			delete(setting, field)
+		}
+	}
+
+	var err error
+	if schema["settings"], err = json.Marshal(settings); err != nil {
+		return nil, err
+	}
+	if raw["settings_schema"], err = json.Marshal(schema); err != nil {
+		return nil, err
+	}
+	return json.Marshal(raw)
+}
+
 // dumpPluginId writes the plugin id from the given manifest to standard out
 func dumpPluginID(manifest *model.Manifest) {
 	fmt.Printf("%s", manifest.Id)
@@ -2,28 +2,53 @@ package api
 
 import (
 	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"math"
+	"net"
 	"net/http"
+	"strconv"
 
 	"github.com/mattermost/mattermost-plugin-splunk/server/config"
 	"github.com/mattermost/mattermost-plugin-splunk/server/splunk"
+	"github.com/mattermost/mattermost-plugin-splunk/server/store"
 
+	"github.com/google/uuid"
 	"github.com/gorilla/mux"
+	"github.com/mattermost/mattermost-server/v6/model"
 )
 
 const (
 	// WebhookEndpoint a
 	WebhookEndpoint = "/alert_action_wh"
+	// ExportEndpoint returns all plugin data, available for system admins only
+	ExportEndpoint = "/admin/export"
+	// ImportEndpoint stores previously exported plugin data, available for system admins only
+	ImportEndpoint = "/admin/import"
+
+	// maxImportSize is the maximum size of the import request body
+	maxImportSize = 50 * 1024 * 1024
+	// maxWebhookSize is the maximum size of the alert action webhook request body
+	maxWebhookSize = 1024 * 1024
 )
 
 // Error - returned error message for api errors
 type Error struct {
 	Message    string `json:"message"`
 	StatusCode int    `json:"status_code"`
+	// CorrelationID identifies rejected alert in the server logs and errors channel
+	CorrelationID string `json:"correlation_id,omitempty"`
+}
+
+// WebhookURL returns URL of the alert action webhook for the alert subscription.
+func WebhookURL(siteURL string, pluginID string, alertID string, secret string) string {
+	return fmt.Sprintf("%s/plugins/%s%s%s?id=%s&secret=%s", siteURL, pluginID, config.APIPath, WebhookEndpoint, alertID, secret)
 }
 
 // NewHTTPHandler initializes the router.
-func NewHTTPHandler(sp splunk.Splunk, config *config.Config) http.Handler {
-	return newHandler(sp, config)
+// Handlers read the current plugin configuration on every request.
+func NewHTTPHandler(sp splunk.Splunk) http.Handler {
+	return newHandler(sp)
 }
 
 // handler is an http.handler for all plugin HTTP endpoints
@@ -32,60 +57,310 @@ type handler struct {
 	sp splunk.Splunk
 }
 
-func newHandler(sp splunk.Splunk, c *config.Config) *handler {
+func newHandler(sp splunk.Splunk) *handler {
 	h := &handler{
 		Router: mux.NewRouter(),
 		sp:     sp,
 	}
+
 	apiRouter := h.Router.PathPrefix(config.APIPath).Subrouter()
 
-	apiRouter.HandleFunc(WebhookEndpoint, h.handleAlertActionWH(c)).Methods(http.MethodPost)
+	apiRouter.HandleFunc(WebhookEndpoint, h.handleAlertActionWH).Methods(http.MethodPost)
+	apiRouter.HandleFunc(splunk.RerunEndpoint, h.requireUser(h.handleRerunAlertSearch)).Methods(http.MethodPost)
+	apiRouter.HandleFunc(splunk.SnoozeEndpoint, h.requireUser(h.handleSnoozeAlert)).Methods(http.MethodPost)
+	apiRouter.HandleFunc(splunk.SetupConnectEndpoint, h.requireUser(h.handleSetupConnect)).Methods(http.MethodPost)
+	apiRouter.HandleFunc(splunk.SetupSubscriptionEndpoint, h.requireSysAdmin(h.handleSetupSubscription)).Methods(http.MethodPost)
+	apiRouter.HandleFunc(splunk.SetupSubscribeEndpoint, h.requireSysAdmin(h.handleSetupSubscribe)).Methods(http.MethodPost)
+	apiRouter.HandleFunc(ExportEndpoint, h.requireSysAdmin(h.handleExport)).Methods(http.MethodGet)
+	apiRouter.HandleFunc(ImportEndpoint, h.requireSysAdmin(h.handleImport)).Methods(http.MethodPost)
 
 	return h
 }
 
-func (h *handler) handleAlertActionWH(config *config.Config) http.HandlerFunc {
-	return func(w http.ResponseWriter, r *http.Request) {
-		var req splunk.AlertActionWHPayload
-		err := json.NewDecoder(r.Body).Decode(&req)
-		if err != nil {
-			h.sp.LogError("Bad Request", "error", err.Error())
-			h.jsonError(w, Error{Message: "Bad Request", StatusCode: http.StatusBadRequest})
-			return
-		}
+func (h *handler) handleAlertActionWH(w http.ResponseWriter, r *http.Request) {
+	conf := h.sp.GetConfiguration()
+	correlationID := model.NewId()
+	id := r.URL.Query().Get("id")
 
-		id := r.URL.Query().Get("id")
-		if id == "" {
-			errMsg := "Bad webhook request. Missing url param 'id'"
-			h.sp.LogError(errMsg)
-			h.jsonError(w, Error{Message: errMsg, StatusCode: http.StatusBadRequest})
-		}
+	// Config is validated in OnConfigurationChange so lists are expected to be parsable.
+	allowedIPs, err := config.ParseCIDRList(conf.WebhookAllowedIPs)
+	if err != nil {
+		h.sp.LogError("Invalid webhook allowed IPs", "error", err.Error())
+	}
+	trustedProxies, err := config.ParseCIDRList(conf.WebhookTrustedProxies)
+	if err != nil {
+		h.sp.LogError("Invalid webhook trusted proxies", "error", err.Error())
+	}
+
+	ip := clientIP(r, trustedProxies)
+	if len(allowedIPs) > 0 && (ip == nil || !containsIP(allowedIPs, ip)) {
+		h.rejectUnauthenticated(w, correlationID, id, ip, Error{Message: "Webhook request from not allowed address", StatusCode: http.StatusForbidden})
+		return
+	}
+
+	if id == "" {
+		h.rejectUnauthenticated(w, correlationID, id, ip, Error{Message: "Bad webhook request. Missing url param 'id'", StatusCode: http.StatusBadRequest})
+		return
+	}
+
+	secret := r.URL.Query().Get("secret")
+	if secret == "" {
+		h.rejectUnauthenticated(w, correlationID, id, ip, Error{Message: "Bad webhook request. Missing url param 'secret'", StatusCode: http.StatusBadRequest})
+		return
+	}
+
+	if secret != conf.Secret {
+		h.rejectUnauthenticated(w, correlationID, id, ip, Error{Message: "Bad webhook request. Invalid 'secret'", StatusCode: http.StatusBadRequest})
+		return
+	}
+
+	retryAfter, err := h.sp.AllowAlert(id, conf.WebhookRateLimit, conf.WebhookGlobalRateLimit)
+	if err != nil {
+		h.sp.LogWarn("Failed to check webhook rate limit", "error", err.Error())
+	}
+	if retryAfter > 0 {
+		w.Header().Set("Retry-After", strconv.Itoa(int(math.Ceil(retryAfter.Seconds()))))
+		h.rejectAlert(w, correlationID, id, ip, Error{Message: "Too many webhook requests", StatusCode: http.StatusTooManyRequests}, nil)
+		return
+	}
+
+	// the body is only read once the request is authorized and its size is limited
+	body, err := ioutil.ReadAll(http.MaxBytesReader(w, r.Body, maxWebhookSize))
+	if err != nil {
+		h.rejectAlert(w, correlationID, id, ip, Error{Message: "Webhook request is too large or unreadable", StatusCode: http.StatusRequestEntityTooLarge}, err)
+		return
+	}
+
+	// payload format is set per subscription so it's decoded once the request is authorized
+	req, err := h.sp.DecodeAlertPayload(id, body)
+	if err == splunk.ErrAlertNotFound {
+		h.rejectAlert(w, correlationID, id, ip, Error{Message: "Bad webhook request. Unknown 'id'", StatusCode: http.StatusNotFound}, nil)
+		return
+	}
+	if err != nil {
+		h.rejectAlert(w, correlationID, id, ip, Error{Message: "Bad Request", StatusCode: http.StatusBadRequest}, err)
+		return
+	}
+
+	err = h.sp.Notify(id, req)
+	if err == splunk.ErrAlertNotFound {
+		h.rejectAlert(w, correlationID, id, ip, Error{Message: "Bad webhook request. Unknown 'id'", StatusCode: http.StatusNotFound}, nil)
+		return
+	}
+	if err == splunk.ErrAlertDisabled {
+		h.rejectAlert(w, correlationID, id, ip, Error{Message: "Alert subscription is disabled", StatusCode: http.StatusForbidden}, nil)
+		return
+	}
+	if err != nil {
+		h.rejectAlert(w, correlationID, id, ip, Error{Message: "Error during webhook notify process", StatusCode: http.StatusInternalServerError}, err)
+		return
+	}
+
+	h.respondWithSuccess(w)
+}
+
+// rejectAlert responds with the error and reports it to admins.
+func (h *handler) rejectAlert(w http.ResponseWriter, correlationID string, alertID string, ip net.IP, e Error, err error) {
+	h.reject(w, splunk.DeliveryError{AlertID: alertID, Err: err}, ip, correlationID, e)
+}
+
+// rejectUnauthenticated responds with the error of the request rejected before its secret is checked
+// and reports it to admins without trusting the alert ID, so random IDs are not reported one by one.
+func (h *handler) rejectUnauthenticated(w http.ResponseWriter, correlationID string, alertID string, ip net.IP, e Error) {
+	h.reject(w, splunk.DeliveryError{AlertID: alertID, Unauthenticated: true}, ip, correlationID, e)
+}
+
+func (h *handler) reject(w http.ResponseWriter, report splunk.DeliveryError, ip net.IP, correlationID string, e Error) {
+	if ip != nil {
+		report.Source = ip.String()
+	}
+	report.CorrelationID = correlationID
+	report.Reason = e.Message
+	h.sp.ReportDeliveryError(report)
+
+	e.CorrelationID = correlationID
+	h.jsonError(w, e)
+}
+
+// handleRerunAlertSearch handles re-run search button of alert posts.
+func (h *handler) handleRerunAlertSearch(w http.ResponseWriter, r *http.Request) {
+	var req model.PostActionIntegrationRequest
+	err := json.NewDecoder(r.Body).Decode(&req)
+	if err != nil {
+		h.sp.LogError("Bad Request", "error", err.Error())
+		h.jsonError(w, Error{Message: "Bad Request", StatusCode: http.StatusBadRequest})
+		return
+	}
+
+	var resp model.PostActionIntegrationResponse
+	err = h.sp.RerunAlertSearch(r.Header.Get("Mattermost-User-Id"), req.PostId)
+	if err != nil {
+		h.sp.LogWarn("Failed to re-run alert search", "post_id", req.PostId, "error", err.Error())
+		resp.EphemeralText = "Failed to re-run the search: " + err.Error()
+	}
+	h.respondWithJSON(w, resp)
+}
+
+// handleSnoozeAlert handles snooze button of alert posts.
+func (h *handler) handleSnoozeAlert(w http.ResponseWriter, r *http.Request) {
+	var req model.PostActionIntegrationRequest
+	err := json.NewDecoder(r.Body).Decode(&req)
+	if err != nil {
+		h.sp.LogError("Bad Request", "error", err.Error())
+		h.jsonError(w, Error{Message: "Bad Request", StatusCode: http.StatusBadRequest})
+		return
+	}
+
+	var resp model.PostActionIntegrationResponse
+	until, err := h.sp.SnoozeAlert(r.Header.Get("Mattermost-User-Id"), req.Context)
+	if err != nil {
+		h.sp.LogWarn("Failed to snooze alert", "post_id", req.PostId, "error", err.Error())
+		resp.EphemeralText = "Failed to snooze the alert: " + err.Error()
+	} else {
+		resp.EphemeralText = "Alerts of the subscription are muted until " + until.UTC().Format("Jan 2, 2006 15:04 MST")
+	}
+	h.respondWithJSON(w, resp)
+}
+
+// handleSetupConnect handles submission of the connect dialog of the setup.
+func (h *handler) handleSetupConnect(w http.ResponseWriter, r *http.Request) {
+	var req model.SubmitDialogRequest
+	err := json.NewDecoder(r.Body).Decode(&req)
+	if err != nil {
+		h.sp.LogError("Bad Request", "error", err.Error())
+		h.jsonError(w, Error{Message: "Bad Request", StatusCode: http.StatusBadRequest})
+		return
+	}
+
+	var resp model.SubmitDialogResponse
+	resp.Errors, err = h.sp.SetupConnect(r.Header.Get("Mattermost-User-Id"), req.ChannelId, req.Submission)
+	if err != nil {
+		h.sp.LogWarn("Failed to connect in setup", "error", err.Error())
+		resp.Error = err.Error()
+	}
+	h.respondWithJSON(w, resp)
+}
+
+// handleSetupSubscription handles the button opening the test subscription dialog of the setup.
+func (h *handler) handleSetupSubscription(w http.ResponseWriter, r *http.Request) {
+	var req model.PostActionIntegrationRequest
+	err := json.NewDecoder(r.Body).Decode(&req)
+	if err != nil {
+		h.sp.LogError("Bad Request", "error", err.Error())
+		h.jsonError(w, Error{Message: "Bad Request", StatusCode: http.StatusBadRequest})
+		return
+	}
+
+	var resp model.PostActionIntegrationResponse
+	err = h.sp.OpenSetupSubscription(r.Header.Get("Mattermost-User-Id"), req.TriggerId, req.ChannelId)
+	if err != nil {
+		h.sp.LogWarn("Failed to open setup subscription dialog", "error", err.Error())
+		resp.EphemeralText = "Failed to open the subscription dialog: " + err.Error()
+	}
+	h.respondWithJSON(w, resp)
+}
+
+// handleSetupSubscribe handles submission of the test subscription dialog of the setup.
+func (h *handler) handleSetupSubscribe(w http.ResponseWriter, r *http.Request) {
+	var req model.SubmitDialogRequest
+	err := json.NewDecoder(r.Body).Decode(&req)
+	if err != nil {
+		h.sp.LogError("Bad Request", "error", err.Error())
+		h.jsonError(w, Error{Message: "Bad Request", StatusCode: http.StatusBadRequest})
+		return
+	}
+
+	var resp model.SubmitDialogResponse
+	channelID, _ := req.Submission["channel_id"].(string)
+	if channelID == "" {
+		resp.Errors = map[string]string{"channel_id": "Select the channel to subscribe"}
+		h.respondWithJSON(w, resp)
+		return
+	}
 
-		secret := r.URL.Query().Get("secret")
-		if secret == "" {
-			errMsg := "Bad webhook request. Missing url param 'secret'"
-			h.sp.LogError(errMsg)
-			h.jsonError(w, Error{Message: errMsg, StatusCode: http.StatusBadRequest})
+	conf := h.sp.GetConfiguration()
+	alertID := uuid.New().String()
+	webhookURL := WebhookURL(conf.WebhookBaseURL(h.sp.GetSiteURL()), conf.PluginID, alertID, conf.Secret)
+	err = h.sp.SetupSubscribe(r.Header.Get("Mattermost-User-Id"), channelID, alertID, webhookURL)
+	if err != nil {
+		h.sp.LogError("Failed to create setup subscription", "error", err.Error())
+		resp.Error = "Failed to create the subscription: " + err.Error()
+	}
+	h.respondWithJSON(w, resp)
+}
+
+// requireUser allows only requests of authenticated users.
+func (h *handler) requireUser(next http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Mattermost-User-Id") == "" {
+			h.jsonError(w, Error{Message: "Not authorized", StatusCode: http.StatusUnauthorized})
 			return
 		}
+		next(w, r)
+	}
+}
 
-		if secret != config.Secret {
-			errMsg := "Bad webhook request. Invalid 'secret'"
-			h.sp.LogError(errMsg)
-			h.jsonError(w, Error{Message: errMsg, StatusCode: http.StatusBadRequest})
+// requireSysAdmin allows only requests of authenticated system admins.
+func (h *handler) requireSysAdmin(next http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		userID := r.Header.Get("Mattermost-User-Id")
+		if userID == "" {
+			h.jsonError(w, Error{Message: "Not authorized", StatusCode: http.StatusUnauthorized})
 			return
 		}
-
-		err = h.sp.Notify(id, req)
-		if err != nil {
-			errMsg := "Error during webhook notify process"
-			h.sp.LogError(errMsg, "error", err.Error())
-			h.jsonError(w, Error{Message: errMsg, StatusCode: http.StatusInternalServerError})
+		if !h.sp.HasPermissionTo(userID, model.PermissionManageSystem) {
+			h.jsonError(w, Error{Message: "You need to be a sysadmin to perform this action", StatusCode: http.StatusForbidden})
 			return
 		}
+		next(w, r)
+	}
+}
+
+func (h *handler) handleExport(w http.ResponseWriter, r *http.Request) {
+	includeTokens, _ := strconv.ParseBool(r.URL.Query().Get("include_tokens"))
+	bundle, err := h.sp.ExportData(includeTokens)
+	h.sp.Audit(store.AuditEntry{
+		ActorID: r.Header.Get("Mattermost-User-Id"),
+		Action:  "data/export",
+		Details: "include_tokens=" + strconv.FormatBool(includeTokens),
+		Result:  splunk.AuditResult(err),
+	})
+	if err != nil {
+		errMsg := "Error while exporting plugin data"
+		h.sp.LogError(errMsg, "error", err.Error())
+		h.jsonError(w, Error{Message: errMsg, StatusCode: http.StatusInternalServerError})
+		return
+	}
+
+	h.sp.LogInfo("Plugin data exported", "user_id", r.Header.Get("Mattermost-User-Id"), "include_tokens", includeTokens)
+	w.Header().Set("Content-Disposition", `attachment; filename="splunk-plugin-export.json"`)
+	h.respondWithJSON(w, bundle)
+}
+
+func (h *handler) handleImport(w http.ResponseWriter, r *http.Request) {
+	var bundle store.Bundle
+	err := json.NewDecoder(http.MaxBytesReader(w, r.Body, maxImportSize)).Decode(&bundle)
+	if err != nil {
+		h.sp.LogError("Bad Request", "error", err.Error())
+		h.jsonError(w, Error{Message: "Bad Request", StatusCode: http.StatusBadRequest})
+		return
+	}
 
-		h.respondWithSuccess(w)
+	summary, err := h.sp.ImportData(bundle)
+	h.sp.Audit(store.AuditEntry{
+		ActorID: r.Header.Get("Mattermost-User-Id"),
+		Action:  "data/import",
+		Result:  splunk.AuditResult(err),
+	})
+	if err != nil {
+		errMsg := "Error while importing plugin data"
+		h.sp.LogError(errMsg, "error", err.Error())
+		h.jsonError(w, Error{Message: errMsg + ". " + err.Error(), StatusCode: http.StatusInternalServerError})
+		return
 	}
+
+	h.sp.LogInfo("Plugin data imported", "user_id", r.Header.Get("Mattermost-User-Id"), "skipped", len(summary.Skipped))
+	h.respondWithJSON(w, summary)
 }
 
 func (h *handler) jsonError(w http.ResponseWriter, err Error) {
@@ -0,0 +1,79 @@
+package api
+
+import (
+	"bytes"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/mattermost/mattermost-plugin-splunk/server/config"
+	"github.com/mattermost/mattermost-plugin-splunk/server/splunk"
+)
+
+type fakeSplunk struct {
+	splunk.Splunk
+	reported []splunk.DeliveryError
+	decoded  int
+}
+
+func (f *fakeSplunk) GetConfiguration() *config.Config {
+	return &config.Config{Secret: "secret"}
+}
+
+func (f *fakeSplunk) LogWarn(_ string, _ ...interface{}) {}
+
+func (f *fakeSplunk) ReportDeliveryError(e splunk.DeliveryError) {
+	f.reported = append(f.reported, e)
+}
+
+func (f *fakeSplunk) AllowAlert(_ string, _ int, _ int) (time.Duration, error) {
+	return 0, nil
+}
+
+func (f *fakeSplunk) DecodeAlertPayload(_ string, _ []byte) (splunk.AlertActionWHPayload, error) {
+	f.decoded++
+	return splunk.AlertActionWHPayload{}, nil
+}
+
+func (f *fakeSplunk) Notify(_ string, _ splunk.AlertActionWHPayload) error {
+	return nil
+}
+
+// countingReader counts bytes read from the request body.
+type countingReader struct {
+	r    *bytes.Reader
+	read int
+}
+
+func (c *countingReader) Read(p []byte) (int, error) {
+	n, err := c.r.Read(p)
+	c.read += n
+	return n, err
+}
+
+func TestHandleAlertActionWHBodyLimit(t *testing.T) {
+	is := assert.New(t)
+	sp := &fakeSplunk{}
+	h := newHandler(sp)
+	large := bytes.Repeat([]byte("a"), maxWebhookSize+1)
+
+	body := &countingReader{r: bytes.NewReader(large)}
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, httptest.NewRequest(http.MethodPost, config.APIPath+WebhookEndpoint+"?id=alert&secret=wrong", body))
+	is.Equal(http.StatusBadRequest, w.Code)
+	is.Zero(body.read, "body of unauthorized request is not read")
+
+	w = httptest.NewRecorder()
+	h.ServeHTTP(w, httptest.NewRequest(http.MethodPost, config.APIPath+WebhookEndpoint+"?id=alert&secret=secret", bytes.NewReader(large)))
+	is.Equal(http.StatusRequestEntityTooLarge, w.Code)
+	is.Zero(sp.decoded)
+
+	w = httptest.NewRecorder()
+	h.ServeHTTP(w, httptest.NewRequest(http.MethodPost, config.APIPath+WebhookEndpoint+"?id=alert&secret=secret", strings.NewReader(`{"sid": "1"}`)))
+	is.Equal(http.StatusOK, w.Code)
+	is.Equal(1, sp.decoded)
+}
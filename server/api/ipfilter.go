@@ -0,0 +1,54 @@
+package api
+
+import (
+	"net"
+	"net/http"
+	"strings"
+)
+
+// clientIP returns address of the client which originated the request.
+// X-Forwarded-For and X-Real-IP headers are used only if the request
+// came from one of the trusted proxies.
+func clientIP(r *http.Request, trustedProxies []*net.IPNet) net.IP {
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		host = r.RemoteAddr
+	}
+	ip := net.ParseIP(host)
+	if ip == nil || !containsIP(trustedProxies, ip) {
+		return ip
+	}
+
+	var forwarded []string
+	for _, h := range r.Header.Values("X-Forwarded-For") {
+		forwarded = append(forwarded, strings.Split(h, ",")...)
+	}
+	if len(forwarded) == 0 {
+		if realIP := net.ParseIP(strings.TrimSpace(r.Header.Get("X-Real-IP"))); realIP != nil {
+			return realIP
+		}
+		return ip
+	}
+
+	// walk proxies chain from the closest one and stop on first untrusted address
+	for i := len(forwarded) - 1; i >= 0; i-- {
+		forwardedIP := net.ParseIP(strings.TrimSpace(forwarded[i]))
+		if forwardedIP == nil {
+			break
+		}
+		ip = forwardedIP
+		if !containsIP(trustedProxies, ip) {
+			break
+		}
+	}
+	return ip
+}
+
+func containsIP(nets []*net.IPNet, ip net.IP) bool {
+	for _, n := range nets {
+		if n.Contains(ip) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,43 @@
+package api
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/mattermost/mattermost-plugin-splunk/server/config"
+)
+
+func Test_clientIP(t *testing.T) {
+	trusted, err := config.ParseCIDRList("127.0.0.1, 10.0.0.0/8")
+	assert.NoError(t, err)
+
+	tests := []struct {
+		name       string
+		remoteAddr string
+		forwarded  string
+		realIP     string
+		want       string
+	}{
+		{name: "direct request", remoteAddr: "192.168.1.15:4312", want: "192.168.1.15"},
+		{name: "untrusted proxy headers are ignored", remoteAddr: "192.168.1.15:4312", forwarded: "8.8.8.8", want: "192.168.1.15"},
+		{name: "trusted proxy", remoteAddr: "127.0.0.1:4312", forwarded: "8.8.8.8", want: "8.8.8.8"},
+		{name: "proxies chain", remoteAddr: "127.0.0.1:4312", forwarded: "1.1.1.1, 8.8.8.8, 10.0.0.2", want: "8.8.8.8"},
+		{name: "real ip header", remoteAddr: "127.0.0.1:4312", realIP: "8.8.8.8", want: "8.8.8.8"},
+		{name: "only trusted proxies", remoteAddr: "127.0.0.1:4312", forwarded: "10.0.0.2", want: "10.0.0.2"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := &http.Request{RemoteAddr: tt.remoteAddr, Header: http.Header{}}
+			if tt.forwarded != "" {
+				r.Header.Set("X-Forwarded-For", tt.forwarded)
+			}
+			if tt.realIP != "" {
+				r.Header.Set("X-Real-IP", tt.realIP)
+			}
+			assert.Equal(t, tt.want, clientIP(r, trusted).String())
+		})
+	}
+}
@@ -0,0 +1,40 @@
+package config
+
+import (
+	"net"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// ParseCIDRList parses comma separated list of CIDR ranges.
+// Single IP addresses are accepted as well and match only the address itself.
+func ParseCIDRList(list string) ([]*net.IPNet, error) {
+	var res []*net.IPNet
+	for _, entry := range strings.Split(list, ",") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+
+		if !strings.Contains(entry, "/") {
+			ip := net.ParseIP(entry)
+			if ip == nil {
+				return nil, errors.Errorf("invalid IP address %q", entry)
+			}
+			bits := 8 * net.IPv4len
+			if ip.To4() == nil {
+				bits = 8 * net.IPv6len
+			}
+			res = append(res, &net.IPNet{IP: ip, Mask: net.CIDRMask(bits, bits)})
+			continue
+		}
+
+		_, ipNet, err := net.ParseCIDR(entry)
+		if err != nil {
+			return nil, errors.Wrapf(err, "invalid CIDR range %q", entry)
+		}
+		res = append(res, ipNet)
+	}
+	return res, nil
+}
@@ -2,7 +2,12 @@ package config
 
 import (
 	"context"
+	"net/url"
 	"reflect"
+	"regexp"
+	"strings"
+
+	"github.com/pkg/errors"
 )
 
 // Config captures the plugin's external Config as exposed in the Mattermost server
@@ -20,8 +25,65 @@ type Config struct {
 	PluginID      string
 	PluginVersion string
 	Secret        string
+
+	// WebhookRateLimit is the number of alerts per minute accepted for a single subscription.
+	WebhookRateLimit int
+	// WebhookGlobalRateLimit is the number of alerts per minute accepted for all subscriptions.
+	WebhookGlobalRateLimit int
+
+	// WebhookAllowedIPs is comma separated list of CIDR ranges allowed to deliver alerts.
+	WebhookAllowedIPs string
+	// WebhookTrustedProxies is comma separated list of CIDR ranges of proxies,
+	// which are trusted to set X-Forwarded-For header.
+	WebhookTrustedProxies string
+
+	// AlertPreviewRows is the number of result rows shown in alert posts, zero disables preview.
+	AlertPreviewRows int
+	// ServiceAccountServer and ServiceAccountToken are used to fetch alert results
+	// when subscription creator's credentials are not available.
+	ServiceAccountServer string
+	ServiceAccountToken  string
+
+	// RemoveOrphanedSubscriptions enables removal of subscriptions whose channel, creator
+	// or saved search no longer exists, otherwise they are only reported in logs.
+	RemoveOrphanedSubscriptions bool
+
+	// SeverityRouting maps alert severities to channels, see ParseSeverityRouting for the format.
+	SeverityRouting string
+
+	// ErrorsChannel is team/channel where rejected alerts and failed posts are reported.
+	ErrorsChannel string
+
+	// DefaultServer is the splunk server base URL used when no server is given to login and the setup.
+	DefaultServer string
+	// ManagementPort is added to splunk Enterprise server URLs without a port, zero leaves them as given.
+	ManagementPort int
+	// WebhookSiteURL replaces the Mattermost site URL in webhook URLs if Splunk reaches Mattermost at another address.
+	WebhookSiteURL string
+	// MaxResultRows is the number of result rows shown for log sources and reports, zero uses the defaults.
+	MaxResultRows int
+
+	// AlertHideRerunButton leaves the re-run search button out of alert posts.
+	AlertHideRerunButton bool
+	// AlertColor is the color of the attachment of alert posts, e.g. #ff0000.
+	AlertColor string
+
+	// MetadataCacheTTL is the number of minutes splunk metadata lookups are cached for, zero disables the cache.
+	MetadataCacheTTL int
+
+	// AuditHECURL is the splunk HTTP Event Collector audit log entries are forwarded to, empty disables forwarding.
+	AuditHECURL string
+	// AuditHECToken is the HTTP Event Collector token.
+	AuditHECToken string
+	// AuditIndex is the index of forwarded audit entries, the default index of the token is used if it's empty.
+	AuditIndex string
 }
 
+// MaxResultRowsLimit is the maximum allowed MaxResultRows
+const MaxResultRowsLimit = 1000
+
+var colorPattern = regexp.MustCompile(`^#([0-9a-fA-F]{3}|[0-9a-fA-F]{6})$`)
+
 // Clone shallow copies the Config. Your implementation may require a deep copy if
 // your Config has reference types.
 func (c *Config) Clone() *Config {
@@ -29,6 +91,88 @@ func (c *Config) Clone() *Config {
 	return &clone
 }
 
+// ResultRows returns MaxResultRows or def if it's not set.
+func (c *Config) ResultRows(def int) int {
+	if c.MaxResultRows == 0 {
+		return def
+	}
+	return c.MaxResultRows
+}
+
+// WebhookBaseURL returns WebhookSiteURL if it's set and siteURL otherwise.
+func (c *Config) WebhookBaseURL(siteURL string) string {
+	if c.WebhookSiteURL == "" {
+		return siteURL
+	}
+	return strings.TrimRight(c.WebhookSiteURL, "/")
+}
+
+// IsValid checks if all the Config values are valid.
+func (c *Config) IsValid() error {
+	if c.WebhookRateLimit < 0 || c.WebhookGlobalRateLimit < 0 {
+		return errors.New("webhook rate limits can't be negative")
+	}
+	if c.AlertPreviewRows < 0 {
+		return errors.New("alert preview rows can't be negative")
+	}
+	if _, err := ParseSeverityRouting(c.SeverityRouting); err != nil {
+		return errors.Wrap(err, "invalid severity routing")
+	}
+	if c.ErrorsChannel != "" {
+		// checked the way it is looked up, team and channel names are split at the only slash
+		parts := strings.Split(c.ErrorsChannel, "/")
+		if len(parts) != 2 || parts[0] == "" || parts[1] == "" {
+			return errors.New("errors channel must be given as team-name/channel-name")
+		}
+	}
+	if err := checkURL(c.DefaultServer); err != nil {
+		return errors.Wrap(err, "invalid default server URL")
+	}
+	if c.ManagementPort < 0 || c.ManagementPort > 65535 {
+		return errors.Errorf("management port %d is not a valid port", c.ManagementPort)
+	}
+	if err := checkURL(c.WebhookSiteURL); err != nil {
+		return errors.Wrap(err, "invalid webhook site URL")
+	}
+	if c.MaxResultRows < 0 || c.MaxResultRows > MaxResultRowsLimit {
+		return errors.Errorf("max result rows must be between 0 and %d", MaxResultRowsLimit)
+	}
+	if err := checkURL(c.AuditHECURL); err != nil {
+		return errors.Wrap(err, "invalid audit HTTP Event Collector URL")
+	}
+	if c.AuditHECURL != "" && c.AuditHECToken == "" {
+		return errors.New("audit HTTP Event Collector token is required to forward audit entries")
+	}
+	if c.MetadataCacheTTL < 0 {
+		return errors.New("metadata cache TTL can't be negative")
+	}
+	if c.AlertColor != "" && !colorPattern.MatchString(c.AlertColor) {
+		return errors.Errorf("alert color %q must be a hex color like #ff0000", c.AlertColor)
+	}
+	if _, err := ParseCIDRList(c.WebhookAllowedIPs); err != nil {
+		return errors.Wrap(err, "invalid webhook allowed IPs")
+	}
+	if _, err := ParseCIDRList(c.WebhookTrustedProxies); err != nil {
+		return errors.Wrap(err, "invalid webhook trusted proxies")
+	}
+	return nil
+}
+
+// checkURL checks that u is empty or an absolute http or https URL.
+func checkURL(u string) error {
+	if u == "" {
+		return nil
+	}
+	parsed, err := url.Parse(u)
+	if err != nil {
+		return err
+	}
+	if (parsed.Scheme != "http" && parsed.Scheme != "https") || parsed.Host == "" {
+		return errors.Errorf("%q must be an http or https URL like https://example.com", u)
+	}
+	return nil
+}
+
 var contextKey = reflect.TypeOf(Config{})
 
 // Context sets config object in context
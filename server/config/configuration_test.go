@@ -0,0 +1,47 @@
+package config
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestConfig_IsValid(t *testing.T) {
+	tests := []struct {
+		name    string
+		config  Config
+		wantErr bool
+	}{
+		{name: "empty", config: Config{}},
+		{name: "defaults", config: Config{DefaultServer: "https://splunk.example.com:8089", WebhookSiteURL: "https://mattermost.example.com", MaxResultRows: 50, AlertColor: "#ff0000"}},
+		{name: "short color", config: Config{AlertColor: "#f00"}},
+		{name: "default server without scheme", config: Config{DefaultServer: "splunk.example.com"}, wantErr: true},
+		{name: "webhook site URL without host", config: Config{WebhookSiteURL: "https://"}, wantErr: true},
+		{name: "management port", config: Config{ManagementPort: 8089}},
+		{name: "negative port", config: Config{ManagementPort: -1}, wantErr: true},
+		{name: "port out of range", config: Config{ManagementPort: 70000}, wantErr: true},
+		{name: "too many rows", config: Config{MaxResultRows: MaxResultRowsLimit + 1}, wantErr: true},
+		{name: "named color", config: Config{AlertColor: "red"}, wantErr: true},
+		{name: "errors channel without team", config: Config{ErrorsChannel: "errors"}, wantErr: true},
+		{name: "errors channel", config: Config{ErrorsChannel: "ops/splunk-errors"}},
+		{name: "errors channel with leading slash", config: Config{ErrorsChannel: "/ops/splunk-errors"}, wantErr: true},
+		{name: "errors channel with trailing slash", config: Config{ErrorsChannel: "ops/splunk-errors/"}, wantErr: true},
+		{name: "errors channel without channel", config: Config{ErrorsChannel: "ops/"}, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.config.IsValid()
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			assert.NoError(t, err)
+		})
+	}
+}
+
+func TestConfig_WebhookBaseURL(t *testing.T) {
+	assert.Equal(t, "https://mattermost.example.com", (&Config{}).WebhookBaseURL("https://mattermost.example.com"))
+	assert.Equal(t, "https://mm.internal", (&Config{WebhookSiteURL: "https://mm.internal/"}).WebhookBaseURL("https://mattermost.example.com"))
+}
@@ -0,0 +1,51 @@
+package config
+
+import (
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// SeverityRoute sends alerts with the severity to the channel.
+type SeverityRoute struct {
+	// Team limits the route to subscriptions in channels of the team, empty Team matches all teams.
+	Team     string
+	Severity string
+	// Channel is either team-name/channel-name or channel-name in the team of the subscription channel.
+	Channel string
+}
+
+// ParseSeverityRouting parses severity routing rules, one rule per line like:
+//
+//	critical = team-name/incidents
+//	team-name/info = alerts-feed
+//
+// Empty lines and lines starting with # are ignored.
+func ParseSeverityRouting(rules string) ([]SeverityRoute, error) {
+	var routes []SeverityRoute
+	for _, line := range strings.Split(rules, "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		parts := strings.SplitN(line, "=", 2)
+		if len(parts) != 2 {
+			return nil, errors.Errorf("invalid routing rule %q, expected [team/]severity = channel", line)
+		}
+
+		var route SeverityRoute
+		key := strings.TrimSpace(parts[0])
+		if idx := strings.Index(key, "/"); idx != -1 {
+			route.Team = strings.TrimSpace(key[:idx])
+			key = strings.TrimSpace(key[idx+1:])
+		}
+		route.Severity = strings.ToLower(key)
+		route.Channel = strings.TrimSpace(parts[1])
+		if route.Severity == "" || route.Channel == "" || strings.Count(route.Channel, "/") > 1 {
+			return nil, errors.Errorf("invalid routing rule %q, expected [team/]severity = channel", line)
+		}
+		routes = append(routes, route)
+	}
+	return routes, nil
+}
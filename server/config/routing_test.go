@@ -0,0 +1,28 @@
+package config
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseSeverityRouting(t *testing.T) {
+	routes, err := ParseSeverityRouting(`
+# global rules
+Critical = eng/incidents
+info=alerts-feed
+
+eng/info = eng-alerts
+`)
+	assert.NoError(t, err)
+	assert.Equal(t, []SeverityRoute{
+		{Severity: "critical", Channel: "eng/incidents"},
+		{Severity: "info", Channel: "alerts-feed"},
+		{Team: "eng", Severity: "info", Channel: "eng-alerts"},
+	}, routes)
+
+	for _, rules := range []string{"critical", "critical = ", " = incidents", "critical = a/b/c"} {
+		_, err = ParseSeverityRouting(rules)
+		assert.Error(t, err, rules)
+	}
+}
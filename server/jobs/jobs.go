@@ -0,0 +1,135 @@
+package jobs
+
+import (
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/mattermost/mattermost-plugin-api/cluster"
+	"github.com/pkg/errors"
+)
+
+// keyPrefix keeps job keys apart from other cluster keys of the plugin
+const keyPrefix = "splunk_"
+
+// Job is a recurring task run by a single plugin instance of the cluster at a time.
+type Job struct {
+	// ID identifies the job across the cluster, it must be unique in the plugin.
+	ID string
+	// Interval is the time between the end of a run and the start of the next one.
+	Interval time.Duration
+	// Rounded runs the job at multiples of Interval, e.g. at full minutes, instead.
+	Rounded bool
+	// Run is the task, panics are recovered and logged.
+	Run func()
+}
+
+// Runner schedules recurring jobs registered by subsystems of the plugin.
+// Jobs are scheduled with the plugin cluster API, whose mutex elects the instance running each job,
+// so a job runs exactly once per interval across the cluster.
+type Runner struct {
+	api cluster.JobPluginAPI
+
+	mu        sync.Mutex
+	jobs      []Job
+	scheduled []*cluster.Job
+	started   bool
+}
+
+// NewRunner creates runner scheduling jobs with the plugin API.
+func NewRunner(api cluster.JobPluginAPI) *Runner {
+	return &Runner{api: api}
+}
+
+// Register adds the job to the runner, it's scheduled right away if the runner is already started.
+func (r *Runner) Register(job Job) error {
+	if job.ID == "" || job.Interval <= 0 || job.Run == nil {
+		return errors.Errorf("job %q must have an ID, a positive interval and a task", job.ID)
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for _, registered := range r.jobs {
+		if registered.ID == job.ID {
+			return errors.Errorf("job %q is already registered", job.ID)
+		}
+	}
+
+	if r.started {
+		if err := r.schedule(job); err != nil {
+			return err
+		}
+	}
+	r.jobs = append(r.jobs, job)
+	return nil
+}
+
+// Start schedules all registered jobs. Jobs scheduled before a failure are closed.
+func (r *Runner) Start() error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.started {
+		return errors.New("job runner is already started")
+	}
+
+	for _, job := range r.jobs {
+		if err := r.schedule(job); err != nil {
+			r.closeScheduled()
+			return err
+		}
+	}
+	r.started = true
+	return nil
+}
+
+// Close stops scheduling jobs on this plugin instance, it waits for running jobs to finish.
+func (r *Runner) Close() {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.closeScheduled()
+	r.started = false
+}
+
+// Exclusive runs task while holding the cluster mutex with the given key,
+// so tasks with the same key don't run concurrently on different plugin instances.
+func (r *Runner) Exclusive(key string, task func()) error {
+	mutex, err := cluster.NewMutex(r.api, keyPrefix+key)
+	if err != nil {
+		return errors.Wrapf(err, "failed to create mutex %s", key)
+	}
+	mutex.Lock()
+	defer mutex.Unlock()
+	r.safeRun(key, task)
+	return nil
+}
+
+func (r *Runner) schedule(job Job) error {
+	wait := cluster.MakeWaitForInterval(job.Interval)
+	if job.Rounded {
+		wait = cluster.MakeWaitForRoundedInterval(job.Interval)
+	}
+
+	scheduled, err := cluster.Schedule(r.api, keyPrefix+job.ID, wait, func() { r.safeRun(job.ID, job.Run) })
+	if err != nil {
+		return errors.Wrapf(err, "failed to schedule job %s", job.ID)
+	}
+	r.scheduled = append(r.scheduled, scheduled)
+	return nil
+}
+
+func (r *Runner) closeScheduled() {
+	for _, job := range r.scheduled {
+		_ = job.Close()
+	}
+	r.scheduled = nil
+}
+
+// safeRun runs task recovering from its panic, which would stop the job on this instance otherwise.
+func (r *Runner) safeRun(id string, task func()) {
+	defer func() {
+		if recovered := recover(); recovered != nil {
+			r.api.LogError("job panicked", "job", id, "error", fmt.Sprint(recovered))
+		}
+	}()
+	task()
+}
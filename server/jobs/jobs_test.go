@@ -0,0 +1,72 @@
+package jobs
+
+import (
+	"testing"
+	"time"
+
+	"github.com/mattermost/mattermost-server/v6/model"
+	"github.com/stretchr/testify/assert"
+)
+
+type fakeClusterAPI struct {
+	kv     map[string][]byte
+	errors []string
+}
+
+func (f *fakeClusterAPI) KVSetWithOptions(key string, value []byte, options model.PluginKVSetOptions) (bool, *model.AppError) {
+	if options.Atomic && string(f.kv[key]) != string(options.OldValue) {
+		return false, nil
+	}
+	if value == nil {
+		delete(f.kv, key)
+	} else {
+		f.kv[key] = value
+	}
+	return true, nil
+}
+
+func (f *fakeClusterAPI) LogError(msg string, _ ...interface{}) {
+	f.errors = append(f.errors, msg)
+}
+
+func (f *fakeClusterAPI) KVGet(key string) ([]byte, *model.AppError) {
+	return f.kv[key], nil
+}
+
+func (f *fakeClusterAPI) KVDelete(key string) *model.AppError {
+	delete(f.kv, key)
+	return nil
+}
+
+func (f *fakeClusterAPI) KVList(_, _ int) ([]string, *model.AppError) {
+	var keys []string
+	for key := range f.kv {
+		keys = append(keys, key)
+	}
+	return keys, nil
+}
+
+func TestRunner_Register(t *testing.T) {
+	r := NewRunner(&fakeClusterAPI{kv: map[string][]byte{}})
+	run := func() {}
+
+	assert.NoError(t, r.Register(Job{ID: "reports", Interval: time.Minute, Run: run}))
+	assert.Error(t, r.Register(Job{ID: "reports", Interval: time.Hour, Run: run}), "duplicate ID")
+	assert.Error(t, r.Register(Job{ID: "", Interval: time.Minute, Run: run}))
+	assert.Error(t, r.Register(Job{ID: "cleanup", Run: run}))
+	assert.Error(t, r.Register(Job{ID: "cleanup", Interval: time.Minute}))
+}
+
+func TestRunner_Exclusive(t *testing.T) {
+	api := &fakeClusterAPI{kv: map[string][]byte{}}
+	r := NewRunner(api)
+
+	ran := false
+	assert.NoError(t, r.Exclusive("onboarding", func() { ran = true }))
+	assert.True(t, ran)
+	assert.Empty(t, api.kv, "mutex is released")
+
+	assert.NoError(t, r.Exclusive("onboarding", func() { panic("boom") }))
+	assert.Equal(t, []string{"job panicked"}, api.errors)
+	assert.Empty(t, api.kv, "mutex is released after panic")
+}
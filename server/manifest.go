@@ -43,6 +43,166 @@ const manifestStr = `
         "regenerate_help_text": "Regenerates the secret for the webhook URL endpoint. Regenerating the secret invalidates your existing Splunk integrations.",
         "placeholder": "",
         "default": null
+      },
+      {
+        "key": "WebhookRateLimit",
+        "display_name": "Alerts Per Minute Per Subscription:",
+        "type": "number",
+        "help_text": "The maximum number of alerts per minute accepted for a single subscription. Alerts over the limit are rejected and a single notice is posted to the channel. Set to 0 to disable.",
+        "placeholder": "",
+        "default": 30
+      },
+      {
+        "key": "WebhookGlobalRateLimit",
+        "display_name": "Alerts Per Minute In Total:",
+        "type": "number",
+        "help_text": "The maximum number of alerts per minute accepted for all subscriptions. When it is exceeded, a notice is posted to the errors channel if one is set. Set to 0 to disable.",
+        "placeholder": "",
+        "default": 300
+      },
+      {
+        "key": "WebhookAllowedIPs",
+        "display_name": "Allowed Webhook Sources:",
+        "type": "text",
+        "help_text": "Comma separated list of IP addresses or CIDR ranges of the Splunk servers allowed to deliver alerts, e.g. 10.0.0.0/8, 192.168.1.15. Leave empty to accept alerts from any address.",
+        "placeholder": "10.0.0.0/8, 192.168.1.15",
+        "default": null
+      },
+      {
+        "key": "WebhookTrustedProxies",
+        "display_name": "Trusted Proxies:",
+        "type": "text",
+        "help_text": "Comma separated list of IP addresses or CIDR ranges of the reverse proxies in front of Mattermost. The X-Forwarded-For header is only used to find the alert source when the request comes from one of these addresses.",
+        "placeholder": "127.0.0.1",
+        "default": null
+      },
+      {
+        "key": "AlertPreviewRows",
+        "display_name": "Alert Result Preview Rows:",
+        "type": "number",
+        "help_text": "The number of result rows fetched from Splunk and shown in the alert post. Results are fetched with the credentials of the user who created the subscription, or with the service account below. Set to 0 to disable the preview.",
+        "placeholder": "",
+        "default": 0
+      },
+      {
+        "key": "ServiceAccountServer",
+        "display_name": "Service Account Server URL:",
+        "type": "text",
+        "help_text": "The base URL of the Splunk management API used with the service account token, e.g. https://splunk.example.com:8089.",
+        "placeholder": "https://splunk.example.com:8089",
+        "default": null
+      },
+      {
+        "key": "ServiceAccountToken",
+        "display_name": "Service Account Token:",
+        "type": "text",
+        "help_text": "The Splunk authentication token used to fetch alert results when the subscription creator is not authenticated.",
+        "placeholder": "",
+        "default": null
+      },
+      {
+        "key": "RemoveOrphanedSubscriptions",
+        "display_name": "Remove Orphaned Subscriptions:",
+        "type": "bool",
+        "help_text": "When true, alert subscriptions whose channel, creator or Splunk saved search no longer exists are removed by a daily cleanup job. Subscriptions of archived channels and deactivated creators are only reported. When false, all of them are only reported in the server logs.",
+        "placeholder": "",
+        "default": false
+      },
+      {
+        "key": "SeverityRouting",
+        "display_name": "Severity Routing:",
+        "type": "longtext",
+        "help_text": "Routes alerts to channels by their severity, taken from the severity or urgency field of the first result row. One rule per line in the form 'severity = team-name/channel-name'. Prefix the severity with a team name, e.g. 'team-name/info = alerts-feed', to apply the rule only to subscriptions in that team; the channel name may then omit the team. Alerts matching no rule are posted to the subscription channel.",
+        "placeholder": "critical = team-name/incidents\ninfo = team-name/alerts-feed",
+        "default": null
+      },
+      {
+        "key": "ErrorsChannel",
+        "display_name": "Errors Channel:",
+        "type": "text",
+        "help_text": "Channel where the plugin reports rejected alerts, e.g. with a bad secret or an unknown subscription ID, and posts it failed to create. Given as 'team-name/channel-name'. Each report has a correlation ID to find the failure in the server logs. Leave empty to only log failures.",
+        "placeholder": "team-name/splunk-errors",
+        "default": null
+      },
+      {
+        "key": "DefaultServer",
+        "display_name": "Default Splunk Server:",
+        "type": "text",
+        "help_text": "The base URL of the Splunk management API used when users log in without giving a server. It is also prefilled in the setup dialog.",
+        "placeholder": "https://splunk.example.com:8089",
+        "default": null
+      },
+      {
+        "key": "ManagementPort",
+        "display_name": "Splunk Management Port:",
+        "type": "number",
+        "help_text": "The port of the Splunk REST API added to Splunk Enterprise server URLs given without a port, e.g. 8089. Leave it at 0 to use the URLs as given. Splunk Cloud always uses port 8089.",
+        "placeholder": "",
+        "default": 0
+      },
+      {
+        "key": "WebhookSiteURL",
+        "display_name": "Webhook Site URL:",
+        "type": "text",
+        "help_text": "The Mattermost URL used in the webhook URLs of new alert subscriptions, if Splunk reaches Mattermost at an address other than the Site URL. Leave empty to use the Site URL.",
+        "placeholder": "https://mattermost.internal.example.com",
+        "default": null
+      },
+      {
+        "key": "MaxResultRows",
+        "display_name": "Max Result Rows:",
+        "type": "number",
+        "help_text": "The number of result rows shown for log sources and scheduled reports, at most 1000. Set to 0 to use the default of 50.",
+        "placeholder": "",
+        "default": 50
+      },
+      {
+        "key": "AlertHideRerunButton",
+        "display_name": "Hide Re-run Button:",
+        "type": "bool",
+        "help_text": "When true, alert posts of saved searches don't have the Re-run search button.",
+        "placeholder": "",
+        "default": false
+      },
+      {
+        "key": "AlertColor",
+        "display_name": "Alert Color:",
+        "type": "text",
+        "help_text": "The color of the attachment of alert posts showing the Re-run search button and its results, as a hex color like #ff0000. Leave empty to use the default color.",
+        "placeholder": "#ff0000",
+        "default": null
+      },
+      {
+        "key": "MetadataCacheTTL",
+        "display_name": "Metadata Cache Minutes:",
+        "type": "number",
+        "help_text": "The number of minutes lookups of Splunk saved searches, indexes and searches are cached for each Splunk user, which saves requests to the Splunk management API. Logging in with a new token or logging out clears the cache of the user. Set to 0 to disable caching.",
+        "placeholder": "",
+        "default": 5
+      },
+      {
+        "key": "AuditHECURL",
+        "display_name": "Audit HTTP Event Collector URL:",
+        "type": "text",
+        "help_text": "The Splunk HTTP Event Collector the plugin audit log is forwarded to, e.g. https://splunk.example.com:8088. Logins, logouts, subscription changes, data exports and imports, secret rotations and other admin actions are sent as a JSON event with the source type mattermost:splunk:audit. Leave empty to only keep the audit log in Mattermost.",
+        "placeholder": "https://splunk.example.com:8088",
+        "default": null
+      },
+      {
+        "key": "AuditHECToken",
+        "display_name": "Audit HTTP Event Collector Token:",
+        "type": "text",
+        "help_text": "The HTTP Event Collector token used to forward the audit log. Required if the collector URL is set.",
+        "placeholder": "",
+        "default": null
+      },
+      {
+        "key": "AuditIndex",
+        "display_name": "Audit Index:",
+        "type": "text",
+        "help_text": "The Splunk index of forwarded audit events. Leave empty to use the default index of the token.",
+        "placeholder": "mattermost_audit",
+        "default": null
       }
     ]
   }
@@ -4,7 +4,10 @@ import (
 	"fmt"
 	"log"
 	"net/url"
+	"strconv"
 	"strings"
+	"time"
+	"unicode"
 
 	"github.com/google/uuid"
 	apicommand "github.com/mattermost/mattermost-plugin-api/experimental/command"
@@ -15,22 +18,44 @@ import (
 	"github.com/mattermost/mattermost-plugin-splunk/server/api"
 	"github.com/mattermost/mattermost-plugin-splunk/server/config"
 	"github.com/mattermost/mattermost-plugin-splunk/server/splunk"
+	"github.com/mattermost/mattermost-plugin-splunk/server/store"
 )
 
 const (
 	helpTextHeader = "###### Mattermost Splunk Plugin - Slash command help\n"
 	helpText       = `
 * /splunk help - print this help message
+* /splunk setup - connect to a splunk server and create a test alert subscription step by step
 * /splunk auth login [server base url] [username/token] - log into the splunk server
 * /splunk auth login [server base url] [username]/[token] - Authenticate to the splunk server
+* /splunk auth login [server base url] [username]/[token] --cloud - Authenticate to Splunk Cloud, detected automatically for *.splunkcloud.com
 * /splunk auth login [server base url] [username] - Login to the splunk server after being autenticate
-* /splunk log list - list names of logs on server
-* /splunk log [logname] - show specific log from server
+* /splunk auth login [username]/[token] - Authenticate to the default server set by the system admin
+* /splunk log source add [alias] [index/sourcetype or SPL] - add a log source shared in this channel, e.g. main/access_combined
+* /splunk log source list - list log sources of this channel, same as /splunk log list
+* /splunk log source remove [alias] - remove a log source from this channel
+* /splunk log [alias] - show events of the last 24 hours from the log source
+* /splunk log export [alias] - send all events of the last 24 hours from the log source to you in a direct message
+* /splunk channel server - show splunk server used by default in this channel
+* /splunk report schedule [saved report] --weekly [weekday] [time] - post the report to this channel every week, e.g. --weekly monday 9am
+* /splunk report schedule [saved report] --daily [time] - post the report to this channel every day
+* /splunk report list - list reports scheduled in this channel
+* /splunk report delete [reportID] - stop posting the report
 `
 	sysAdminHelp = `
-* /splunk alert subscribe - subscribe to alerts
+* /splunk alert subscribe [--name name] - subscribe to alerts, the name labels the alert posts
+* /splunk alert add [--name name] - same as alert subscribe
 * /splunk alert list - List all alerts
-* /splunk alert delete [alertID] - Remove an alert
+* /splunk alert edit [name|alertID] --name [new name] - Rename an alert
+* /splunk alert enable [name|alertID] - Enable an alert disabled because its creator left or was deactivated
+* /splunk alert mute [name|alertID] [--for duration] - Stop posting alerts for the duration like 30m, 2h or 3d, 1h by default
+* /splunk alert unmute [name|alertID] - Post alerts of a muted alert again
+* /splunk alert delete [name|alertID] - Remove an alert
+* /splunk alert template [name|alertID] [template] - Format posts of the alert with a Go template, omit the template to use the default format
+* /splunk alert format [name|alertID] [standard|tokenized|raw] - Set the payload format of the alert webhook requests
+* /splunk channel set-server [server base url] - use the server by default for all commands in this channel
+* /splunk channel unset-server - stop using a default server in this channel
+* /splunk audit [count] - Show the latest entries of the audit log, 20 by default
 	`
 	autoCompleteDescription = ""
 	autoCompleteHint        = ""
@@ -52,6 +77,32 @@ type HandlerMap struct {
 	defaultHandler HandlerFunc
 }
 
+// auditedCommands are recorded in the audit log, they change credentials, subscriptions or channel settings
+var auditedCommands = map[string]bool{
+	"alert/subscribe":      true,
+	"alert/add":            true,
+	"alert/edit":           true,
+	"alert/enable":         true,
+	"alert/mute":           true,
+	"alert/unmute":         true,
+	"alert/delete":         true,
+	"alert/template":       true,
+	"alert/format":         true,
+	"log/source/add":       true,
+	"log/source/remove":    true,
+	"auth/login":           true,
+	"auth/logout":          true,
+	"channel/set-server":   true,
+	"channel/unset-server": true,
+	"report/schedule":      true,
+	"report/delete":        true,
+}
+
+// defaultAuditCount is the number of audit log entries shown by default
+const defaultAuditCount = 20
+
+var auditCellReplacer = strings.NewReplacer("|", "\\|", "\r\n", " ", "\n", " ")
+
 // command stores command specific information
 type CommandHandler struct {
 	args    *model.CommandArgs
@@ -74,7 +125,7 @@ func (p *Plugin) GetSlashCommand() (*model.Command, error) {
 	}
 
 	splunk := model.NewAutocompleteData(
-		slashCommandName, "[alert|auth|help|log]", "connect to and interact with splunk.")
+		slashCommandName, "[alert|audit|auth|channel|help|log|report|setup]", "connect to and interact with splunk.")
 	addSubCommands(splunk)
 
 	return &model.Command{
@@ -97,7 +148,16 @@ func (p *Plugin) newCommand(args *model.CommandArgs) *CommandHandler {
 		api:    p.API,
 	}
 
-	err := p.sp.SyncUser(args.UserId)
+	server, err := p.sp.ChannelBinding(args.ChannelId)
+	if err != nil {
+		log.Printf("Error occurred while loading channel server stored in KVStore :%v\n", err)
+	}
+
+	if server != "" {
+		err = p.sp.SyncUserForServer(args.UserId, server)
+	} else {
+		err = p.sp.SyncUser(args.UserId)
+	}
 	if err != nil {
 		log.Printf("Error occurred while syncing user stored in KVStore :%v\n", err)
 	}
@@ -105,15 +165,38 @@ func (p *Plugin) newCommand(args *model.CommandArgs) *CommandHandler {
 	c.handler = HandlerMap{
 		handlers: map[string]HandlerFunc{
 			"alert/subscribe": c.subscribeAlert,
+			"alert/add":       c.subscribeAlert,
 			"alert/list":      c.listAlert,
+			"alert/edit":      c.editAlert,
+			"alert/enable":    c.enableAlert,
+			"alert/mute":      c.muteAlert,
+			"alert/unmute":    c.unmuteAlert,
 			"alert/delete":    c.deleteAlert,
+			"alert/template":  c.setAlertTemplate,
+			"alert/format":    c.setAlertFormat,
 
-			"log":      c.getLogs,
-			"log/list": c.getLogSourceList,
+			"log":               c.getLogs,
+			"log/list":          c.getLogSourceList,
+			"log/export":        c.exportLogs,
+			"log/source":        c.getLogSourceList,
+			"log/source/add":    c.addLogSource,
+			"log/source/list":   c.getLogSourceList,
+			"log/source/remove": c.removeLogSource,
 
 			"auth/user":   c.authUser,
 			"auth/login":  c.authLogin,
 			"auth/logout": c.authLogout,
+
+			"channel/server":       c.channelServer,
+			"channel/set-server":   c.setChannelServer,
+			"channel/unset-server": c.unsetChannelServer,
+
+			"report/schedule": c.scheduleReport,
+			"report/list":     c.listReports,
+			"report/delete":   c.deleteReport,
+
+			"audit": c.auditLog,
+			"setup": c.setup,
 		},
 		defaultHandler: c.help,
 	}
@@ -128,14 +211,50 @@ func (c *CommandHandler) Handle(args ...string) (string, error) {
 	args = args[1:]
 
 	for n := len(args); n > 0; n-- {
-		h := ch.handlers[strings.Join(args[:n], "/")]
+		key := strings.Join(args[:n], "/")
+		h := ch.handlers[key]
 		if h != nil {
-			return h(args[n:]...)
+			message, err := h(args[n:]...)
+			if auditedCommands[key] {
+				c.auditCommand(key, args[n:], message, err)
+			}
+			return message, err
 		}
 	}
 	return ch.defaultHandler(args...)
 }
 
+// auditCommand records the command in the audit log, secrets in its arguments are left out.
+func (c *CommandHandler) auditCommand(key string, args []string, message string, err error) {
+	if key == "auth/login" {
+		args = redactCredentials(args)
+	}
+
+	result := strings.SplitN(message, "\n", 2)[0]
+	if err != nil {
+		result = err.Error()
+	}
+	c.splunk.Audit(store.AuditEntry{
+		ActorID:   c.args.UserId,
+		Action:    key,
+		ChannelID: c.args.ChannelId,
+		Details:   strings.Join(args, " "),
+		Result:    result,
+	})
+}
+
+// redactCredentials hides tokens of auth login arguments, credentials are given as username/token.
+func redactCredentials(args []string) []string {
+	redacted := make([]string, 0, len(args))
+	for _, arg := range args {
+		if parts := strings.SplitN(arg, "/", 2); len(parts) == 2 && !strings.Contains(arg, "://") {
+			arg = parts[0] + "/***"
+		}
+		redacted = append(redacted, arg)
+	}
+	return redacted
+}
+
 func (c *CommandHandler) help(_ ...string) (string, error) {
 	isAuthorized, err := isAuthorizedSysAdmin(c.api, c.args.UserId)
 	if err != nil {
@@ -151,7 +270,67 @@ func (c *CommandHandler) help(_ ...string) (string, error) {
 	return helpText, nil
 }
 
-func (c *CommandHandler) subscribeAlert(_ ...string) (string, error) {
+func (c *CommandHandler) auditLog(args ...string) (string, error) {
+	isAuthorized, err := isAuthorizedSysAdmin(c.api, c.args.UserId)
+	if err != nil {
+		return "", errors.New("There was an error retrieving the user")
+	}
+
+	if !isAuthorized {
+		return "", errors.New("You need to be a sysadmin to perform this action")
+	}
+
+	count := defaultAuditCount
+	if len(args) > 1 {
+		return "Please enter correct number of arguments", nil
+	}
+	if len(args) == 1 {
+		if count, err = strconv.Atoi(args[0]); err != nil || count <= 0 {
+			return "Count must be a positive number", nil
+		}
+	}
+
+	entries, err := c.splunk.AuditLog(count)
+	if err != nil {
+		c.splunk.LogError("error while loading audit log", "error", err.Error())
+		return "Error while loading audit log. " + err.Error(), nil
+	}
+	if len(entries) == 0 {
+		return "Audit log is empty", nil
+	}
+
+	usernames := map[string]string{"": "plugin"}
+	var sb strings.Builder
+	sb.WriteString("| Time | User | Action | Details | Result |\n| :- | :- | :- | :- | :- |\n")
+	for _, entry := range entries {
+		username, ok := usernames[entry.ActorID]
+		if !ok {
+			username = entry.ActorID
+			if user, appErr := c.api.GetUser(entry.ActorID); appErr == nil {
+				username = "@" + user.Username
+			}
+			usernames[entry.ActorID] = username
+		}
+		sb.WriteString(fmt.Sprintf("| %s | %s | %s | %s | %s |\n",
+			time.Unix(0, entry.Time*int64(time.Millisecond)).UTC().Format(time.RFC3339),
+			username,
+			auditCellReplacer.Replace(entry.Action),
+			auditCellReplacer.Replace(entry.Details),
+			auditCellReplacer.Replace(entry.Result)))
+	}
+	return sb.String(), nil
+}
+
+func (c *CommandHandler) setup(_ ...string) (string, error) {
+	err := c.splunk.StartSetup(c.args.UserId, c.args.TriggerId)
+	if err != nil {
+		c.splunk.LogError("error while starting setup", "error", err.Error())
+		return "", errors.New("There was an error opening the setup dialog")
+	}
+	return "", nil
+}
+
+func (c *CommandHandler) subscribeAlert(args ...string) (string, error) {
 	isAuthorized, err := isAuthorizedSysAdmin(c.api, c.args.UserId)
 	if err != nil {
 		c.splunk.LogError("error while subscribing alert, couldn't retrieve the user", "error", err.Error())
@@ -162,8 +341,20 @@ func (c *CommandHandler) subscribeAlert(_ ...string) (string, error) {
 		return "", errors.New("You need to be a sysadmin to perform this action")
 	}
 
-	message, id := alertSubscriptionMessage(c.args.SiteURL, c.config.Secret)
-	err = c.splunk.AddAlert(c.args.ChannelId, id)
+	args, name, ok := parseNameFlag(args)
+	if !ok || len(args) != 0 {
+		return "Please enter correct number of arguments", nil
+	}
+
+	message, id := alertSubscriptionMessage(c.config.WebhookBaseURL(c.args.SiteURL), c.config.PluginID, c.config.Secret, name)
+	err = c.splunk.AddAlert(store.Alert{
+		ID:        id,
+		ChannelID: c.args.ChannelId,
+		Name:      strings.ToLower(name),
+		CreatorID: c.args.UserId,
+		Server:    c.splunk.User().Server,
+		UserName:  c.splunk.User().UserName,
+	})
 	if err != nil {
 		c.splunk.LogError("error while subscribing alert", "error", err.Error())
 		message = err.Error()
@@ -182,15 +373,132 @@ func (c *CommandHandler) listAlert(_ ...string) (string, error) {
 		return "", errors.New("You need to be a sysadmin to perform this action")
 	}
 
-	list, err := c.splunk.ListAlert(c.args.ChannelId)
+	alerts, err := c.splunk.ListAlert(c.args.ChannelId)
 	if err != nil {
 		c.splunk.LogError("error while listing alerts", "error", err.Error())
 		return err.Error(), err
 	}
 
+	var list []string
+	for _, alert := range alerts {
+		item := alert.ID
+		if alert.Name != "" {
+			item = fmt.Sprintf("**%s** (%s)", alert.Name, alert.ID)
+		}
+		if alert.SearchName != "" {
+			item += fmt.Sprintf(", last triggered by %s", alert.SearchName)
+		}
+		if alert.DisabledReason != "" {
+			item += fmt.Sprintf(", disabled because %s", alert.DisabledReason)
+		}
+		if alert.MutedUntil > model.GetMillis() {
+			item += fmt.Sprintf(", muted until %s", time.Unix(0, alert.MutedUntil*int64(time.Millisecond)).UTC().Format("Jan 2, 2006 15:04 MST"))
+		}
+		list = append(list, item)
+	}
 	return createMDForLogsList(list, "No alerts available"), nil
 }
 
+func (c *CommandHandler) editAlert(args ...string) (string, error) {
+	isAuthorized, err := isAuthorizedSysAdmin(c.api, c.args.UserId)
+	if err != nil {
+		return "", errors.New("There was an error retrieving the user")
+	}
+
+	if !isAuthorized {
+		return "", errors.New("You need to be a sysadmin to perform this action")
+	}
+
+	args, name, ok := parseNameFlag(args)
+	if !ok || len(args) != 1 || name == "" {
+		return "Please enter correct number of arguments", nil
+	}
+
+	err = c.splunk.RenameAlert(c.args.ChannelId, args[0], name)
+	if err != nil {
+		c.splunk.LogError("error while editing alert", "error", err.Error())
+		return "Error while editing alert. " + err.Error(), nil
+	}
+
+	return fmt.Sprintf("Alert is now named %s", strings.ToLower(name)), nil
+}
+
+func (c *CommandHandler) enableAlert(args ...string) (string, error) {
+	isAuthorized, err := isAuthorizedSysAdmin(c.api, c.args.UserId)
+	if err != nil {
+		return "", errors.New("There was an error retrieving the user")
+	}
+
+	if !isAuthorized {
+		return "", errors.New("You need to be a sysadmin to perform this action")
+	}
+
+	if len(args) != 1 {
+		return "Please enter correct number of arguments", nil
+	}
+
+	err = c.splunk.EnableAlert(c.args.ChannelId, args[0], c.args.UserId)
+	if err != nil {
+		c.splunk.LogError("error while enabling alert", "error", err.Error())
+		return "Error while enabling alert. " + err.Error(), nil
+	}
+
+	return "Alert is enabled, its results are fetched with your Splunk credentials", nil
+}
+
+func (c *CommandHandler) muteAlert(args ...string) (string, error) {
+	isAuthorized, err := isAuthorizedSysAdmin(c.api, c.args.UserId)
+	if err != nil {
+		return "", errors.New("There was an error retrieving the user")
+	}
+
+	if !isAuthorized {
+		return "", errors.New("You need to be a sysadmin to perform this action")
+	}
+
+	args, period, ok := parseFlag(args, "--for")
+	if !ok || len(args) != 1 {
+		return "Please enter correct number of arguments", nil
+	}
+
+	d := splunk.SnoozeDuration
+	if period != "" {
+		if d, err = parseMuteDuration(period); err != nil {
+			return "Duration must be given like 30m, 2h or 3d", nil
+		}
+	}
+
+	until, err := c.splunk.MuteAlert(c.args.ChannelId, args[0], c.args.UserId, d)
+	if err != nil {
+		c.splunk.LogError("error while muting alert", "error", err.Error())
+		return "Error while muting alert. " + err.Error(), nil
+	}
+
+	return "Alert is muted until " + until.UTC().Format("Jan 2, 2006 15:04 MST"), nil
+}
+
+func (c *CommandHandler) unmuteAlert(args ...string) (string, error) {
+	isAuthorized, err := isAuthorizedSysAdmin(c.api, c.args.UserId)
+	if err != nil {
+		return "", errors.New("There was an error retrieving the user")
+	}
+
+	if !isAuthorized {
+		return "", errors.New("You need to be a sysadmin to perform this action")
+	}
+
+	if len(args) != 1 {
+		return "Please enter correct number of arguments", nil
+	}
+
+	if err = c.splunk.UnmuteAlert(c.args.ChannelId, args[0], c.args.UserId); err != nil {
+		c.splunk.LogError("error while unmuting alert", "error", err.Error())
+		return "Error while unmuting alert. " + err.Error(), nil
+	}
+
+	return "Alert is unmuted", nil
+}
+
 func (c *CommandHandler) deleteAlert(args ...string) (string, error) {
 	isAuthorized, err := isAuthorizedSysAdmin(c.api, c.args.UserId)
 	if err != nil {
@@ -215,21 +523,160 @@ func (c *CommandHandler) deleteAlert(args ...string) (string, error) {
 	return message, nil
 }
 
+func (c *CommandHandler) setAlertTemplate(args ...string) (string, error) {
+	isAuthorized, err := isAuthorizedSysAdmin(c.api, c.args.UserId)
+	if err != nil {
+		return "", errors.New("There was an error retrieving the user")
+	}
+
+	if !isAuthorized {
+		return "", errors.New("You need to be a sysadmin to perform this action")
+	}
+
+	if len(args) < 1 {
+		return "Please enter correct number of arguments", nil
+	}
+
+	// template is taken from the raw command to keep its whitespace and new lines
+	text := textAfterFields(c.args.Command, 4)
+	err = c.splunk.SetAlertTemplate(c.args.ChannelId, args[0], text)
+	if err != nil {
+		c.splunk.LogError("error while setting alert template", "error", err.Error())
+		return "Error while setting alert template. " + err.Error(), nil
+	}
+
+	if text == "" {
+		return "Alert will be posted in the default format", nil
+	}
+	return "Successfully set alert template", nil
+}
+
+func (c *CommandHandler) setAlertFormat(args ...string) (string, error) {
+	isAuthorized, err := isAuthorizedSysAdmin(c.api, c.args.UserId)
+	if err != nil {
+		return "", errors.New("There was an error retrieving the user")
+	}
+
+	if !isAuthorized {
+		return "", errors.New("You need to be a sysadmin to perform this action")
+	}
+
+	if len(args) != 2 {
+		return "Please enter correct number of arguments", nil
+	}
+
+	err = c.splunk.SetAlertPayloadFormat(c.args.ChannelId, args[0], args[1])
+	if err != nil {
+		c.splunk.LogError("error while setting alert payload format", "error", err.Error())
+		return "Error while setting alert payload format. " + err.Error(), nil
+	}
+
+	return fmt.Sprintf("Alert payloads will be decoded in the %s format", strings.ToLower(args[1])), nil
+}
+
 func (c *CommandHandler) getLogs(args ...string) (string, error) {
 	if len(args) != 1 {
 		return "Please enter correct number of arguments", nil
 	}
 
-	logResults, err := c.splunk.Logs(args[0])
+	logResults, err := c.splunk.Logs(c.args.ChannelId, args[0])
 	if err != nil {
-		return "Error while retrieving logs", nil
+		c.splunk.LogError("error while retrieving logs", "error", err.Error())
+		return "Error while retrieving logs. " + err.Error(), nil
 	}
 
 	return createMDForLogs(logResults), nil
 }
 
+func (c *CommandHandler) exportLogs(args ...string) (string, error) {
+	if len(args) != 1 {
+		return "Please enter correct number of arguments", nil
+	}
+
+	err := c.splunk.ExportLogs(c.args.UserId, c.args.ChannelId, args[0])
+	if err != nil {
+		c.splunk.LogError("error while exporting logs", "error", err.Error())
+		return "Error while exporting logs. " + err.Error(), nil
+	}
+
+	return "Exporting events, they will be sent to you in a direct message from the Splunk bot", nil
+}
+
+// reservedLogSourceAliases are log subcommands, log sources with these aliases couldn't be shown.
+var reservedLogSourceAliases = map[string]bool{
+	"list":   true,
+	"export": true,
+	"source": true,
+}
+
+func (c *CommandHandler) addLogSource(args ...string) (string, error) {
+	if len(args) < 2 {
+		return "Please enter correct number of arguments", nil
+	}
+
+	if reservedLogSourceAliases[strings.ToLower(args[0])] {
+		return fmt.Sprintf("Alias %s is a log subcommand, please use another alias", args[0]), nil
+	}
+
+	// definition is taken from the raw command to keep SPL as it was typed
+	definition := textAfterFields(c.args.Command, 5)
+	source, err := c.splunk.AddLogSource(c.args.ChannelId, c.args.UserId, args[0], definition)
+	if err != nil {
+		c.splunk.LogError("error while adding log source", "error", err.Error())
+		return "Error while adding log source. " + err.Error(), nil
+	}
+
+	return fmt.Sprintf("Added log source %s, use /splunk log %s to see its events", source.Alias, source.Alias), nil
+}
+
 func (c *CommandHandler) getLogSourceList(_ ...string) (string, error) {
-	return createMDForLogsList(c.splunk.ListLogs(), "No logs available"), nil
+	sources, err := c.splunk.ListLogSources(c.args.ChannelId)
+	if err != nil {
+		c.splunk.LogError("error while listing log sources", "error", err.Error())
+		return err.Error(), err
+	}
+
+	var list []string
+	for _, source := range sources {
+		list = append(list, fmt.Sprintf("%s: `%s`", source.Alias, splunk.LogSourceSearch(source)))
+	}
+	return createMDForLogsList(list, "No log sources in this channel, add one with /splunk log source add [alias] [index/sourcetype or SPL]"), nil
+}
+
+func (c *CommandHandler) removeLogSource(args ...string) (string, error) {
+	if len(args) != 1 {
+		return "Please enter correct number of arguments", nil
+	}
+
+	sources, err := c.splunk.ListLogSources(c.args.ChannelId)
+	if err != nil {
+		c.splunk.LogError("error while listing log sources", "error", err.Error())
+		return err.Error(), err
+	}
+
+	isCreator := false
+	for _, source := range sources {
+		if source.Alias == strings.ToLower(args[0]) && source.CreatorID == c.args.UserId {
+			isCreator = true
+		}
+	}
+	if !isCreator {
+		isAuthorized, authErr := isAuthorizedSysAdmin(c.api, c.args.UserId)
+		if authErr != nil {
+			return "", errors.New("There was an error retrieving the user")
+		}
+		if !isAuthorized {
+			return "", errors.New("Only the creator of the log source or a sysadmin can remove it")
+		}
+	}
+
+	var message = "Successfully removed log source"
+	if err = c.splunk.RemoveLogSource(c.args.ChannelId, args[0]); err != nil {
+		c.splunk.LogError("error while removing log source", "error", err.Error())
+		message = "Error while removing log source. " + err.Error()
+	}
+
+	return message, nil
 }
 
 func (c *CommandHandler) authUser(_ ...string) (string, error) {
@@ -237,6 +684,10 @@ func (c *CommandHandler) authUser(_ ...string) (string, error) {
 }
 
 func (c *CommandHandler) authLogin(args ...string) (string, error) {
+	args, serverType := parseServerType(args)
+	if len(args) == 1 && c.config.DefaultServer != "" {
+		args = append([]string{c.config.DefaultServer}, args...)
+	}
 	if len(args) < 2 {
 		return "Must have 2 arguments", nil
 	}
@@ -246,9 +697,9 @@ func (c *CommandHandler) authLogin(args ...string) (string, error) {
 		return "Bad server URL", nil
 	}
 
-	err = c.splunk.LoginUser(c.args.UserId, u, args[1])
+	err = c.splunk.LoginUser(c.args.UserId, u, serverType, args[1])
 	if err != nil {
-		return "Wrong credentials: " + err.Error(), nil
+		return "Failed to log in: " + err.Error(), nil
 	}
 
 	return "Successfully authenticated", nil
@@ -259,37 +710,147 @@ func (c *CommandHandler) authLogout(_ ...string) (string, error) {
 	return "Successful logout", nil
 }
 
-func createMDForLogs(results splunk.LogResults) string {
-	fieldNames := make(map[string]int)
-	index := 0
-	res := "|"
-	for _, result := range results.Results {
-		for _, field := range result.Fields {
-			_, ok := fieldNames[field.Name]
-			if !ok {
-				fieldNames[field.Name] = index
-				index++
-				res += " " + field.Name + " |"
-			}
+func (c *CommandHandler) channelServer(_ ...string) (string, error) {
+	server, err := c.splunk.ChannelBinding(c.args.ChannelId)
+	if err != nil {
+		c.splunk.LogError("error while getting channel server", "error", err.Error())
+		return "", errors.New("There was an error retrieving the channel server")
+	}
+
+	if server == "" {
+		return "No default server is set for this channel", nil
+	}
+	return fmt.Sprintf("Default server for this channel : %s", server), nil
+}
+
+func (c *CommandHandler) setChannelServer(args ...string) (string, error) {
+	isAuthorized, err := isAuthorizedSysAdmin(c.api, c.args.UserId)
+	if err != nil {
+		return "", errors.New("There was an error retrieving the user")
+	}
+
+	if !isAuthorized {
+		return "", errors.New("You need to be a sysadmin to perform this action")
+	}
+
+	if len(args) != 1 {
+		return "Please enter correct number of arguments", nil
+	}
+
+	u, err := parseServerAlias(args[0])
+	if err != nil {
+		return "Bad server URL", nil
+	}
+	if u, _, err = splunk.ServerURL(u, "", c.config.ManagementPort); err != nil {
+		return "Bad server URL", nil
+	}
+
+	err = c.splunk.BindChannel(c.args.ChannelId, u)
+	if err != nil {
+		c.splunk.LogError("error while setting channel server", "error", err.Error())
+		return "Error while setting channel server. " + err.Error(), nil
+	}
+
+	return fmt.Sprintf("All commands in this channel will use %s by default", u), nil
+}
+
+func (c *CommandHandler) unsetChannelServer(_ ...string) (string, error) {
+	isAuthorized, err := isAuthorizedSysAdmin(c.api, c.args.UserId)
+	if err != nil {
+		return "", errors.New("There was an error retrieving the user")
+	}
+
+	if !isAuthorized {
+		return "", errors.New("You need to be a sysadmin to perform this action")
+	}
+
+	err = c.splunk.UnbindChannel(c.args.ChannelId)
+	if err != nil {
+		c.splunk.LogError("error while removing channel server", "error", err.Error())
+		return "Error while removing channel server. " + err.Error(), nil
+	}
+
+	return "Commands in this channel will use your current server", nil
+}
+
+func (c *CommandHandler) scheduleReport(args ...string) (string, error) {
+	schedule, err := parseReportSchedule(args)
+	if err != nil {
+		return err.Error(), nil
+	}
+
+	schedule.ChannelID = c.args.ChannelId
+	schedule.CreatorID = c.args.UserId
+	schedule.Location = "UTC"
+	if user, appErr := c.api.GetUser(c.args.UserId); appErr == nil {
+		if tz := user.GetPreferredTimezone(); tz != "" {
+			schedule.Location = tz
 		}
 	}
 
-	res += "\n| :- | :- | :- |\n"
-	var fields = make([]string, len(fieldNames))
-	for _, result := range results.Results {
-		for i := range fields {
-			fields[i] = ""
+	schedule, err = c.splunk.ScheduleReport(schedule)
+	if err != nil {
+		c.splunk.LogError("error while scheduling report", "error", err.Error())
+		return "Error while scheduling report. " + err.Error(), nil
+	}
+
+	return fmt.Sprintf("Scheduled report %s, first delivery at %s. Report ID: %s",
+		schedule.Report, schedule.NextRun.Format(time.RFC1123), schedule.ID), nil
+}
+
+func (c *CommandHandler) listReports(_ ...string) (string, error) {
+	schedules, err := c.splunk.ListReports(c.args.ChannelId)
+	if err != nil {
+		c.splunk.LogError("error while listing reports", "error", err.Error())
+		return err.Error(), err
+	}
+
+	var list []string
+	for _, schedule := range schedules {
+		list = append(list, fmt.Sprintf("%s - %s, next delivery at %s",
+			schedule.ID, schedule.Report, schedule.NextRun.Format(time.RFC1123)))
+	}
+	return createMDForLogsList(list, "No reports scheduled"), nil
+}
+
+func (c *CommandHandler) deleteReport(args ...string) (string, error) {
+	if len(args) != 1 {
+		return "Please enter correct number of arguments", nil
+	}
+
+	schedules, err := c.splunk.ListReports(c.args.ChannelId)
+	if err != nil {
+		c.splunk.LogError("error while listing reports", "error", err.Error())
+		return err.Error(), err
+	}
+
+	isCreator := false
+	for _, schedule := range schedules {
+		if schedule.ID == args[0] && schedule.CreatorID == c.args.UserId {
+			isCreator = true
 		}
-		for _, field := range result.Fields {
-			ind := fieldNames[field.Name]
-			fields[ind] = field.Value.Text
+	}
+	if !isCreator {
+		isAuthorized, authErr := isAuthorizedSysAdmin(c.api, c.args.UserId)
+		if authErr != nil {
+			return "", errors.New("There was an error retrieving the user")
 		}
-		res += "|"
-		for i := range fields {
-			res += " " + fields[i] + " |"
+		if !isAuthorized {
+			return "", errors.New("Only the creator of the report or a sysadmin can remove it")
 		}
-		res += "\n"
 	}
+
+	var message = "Successfully removed report"
+	if _, err = c.splunk.DeleteReport(c.args.ChannelId, args[0]); err != nil {
+		c.splunk.LogError("error while deleting report", "error", err.Error())
+		message = "Error while removing report. " + err.Error()
+	}
+
+	return message, nil
+}
+
+func createMDForLogs(results splunk.LogResults) string {
+	res := results.MarkdownTable()
 	if res == "" {
 		return "Log is empty"
 	}
@@ -309,24 +870,64 @@ func createMDForLogsList(results []string, fallback string) string {
 
 func addSubCommands(splunk *model.AutocompleteData) {
 	splunk.AddCommand(createAlertCommand())
+	splunk.AddCommand(createAuditCommand())
 	splunk.AddCommand(createAuthCommand())
+	splunk.AddCommand(createChannelCommand())
 	splunk.AddCommand(createLogCommand())
+	splunk.AddCommand(createReportCommand())
+	splunk.AddCommand(createSetupCommand())
 	splunk.AddCommand(createHelpCommand())
 }
 
 func createAlertCommand() *model.AutocompleteData {
 	alert := model.NewAutocompleteData(
-		"alert", "[command]", "Available commands: subscribe, list, delete")
+		"alert", "[command]", "Available commands: subscribe, add, list, edit, enable, mute, unmute, delete, template, format")
 
 	subscribe := model.NewAutocompleteData(
-		"subscribe", "", "Subscribe to an alert")
+		"subscribe", "[--name name]", "Subscribe to an alert")
+	subscribe.AddTextArgument("Optional name of the subscription", "[--name name]", "")
 	alert.AddCommand(subscribe)
 
+	add := model.NewAutocompleteData(
+		"add", "[--name name]", "Subscribe to an alert")
+	add.AddTextArgument("Optional name of the subscription", "[--name name]", "")
+	alert.AddCommand(add)
+
+	editAlert := model.NewAutocompleteData(
+		"edit", "[name|alertID] --name [new name]", "Rename an alert")
+	editAlert.AddTextArgument("Name or AlertId and the new name", "[name|alertid] --name [new name]", "")
+	alert.AddCommand(editAlert)
+
+	enableAlert := model.NewAutocompleteData(
+		"enable", "[name|alertID]", "Enable a disabled alert")
+	enableAlert.AddTextArgument("Name or AlertId to enable", "[name|alertid]", "")
+	alert.AddCommand(enableAlert)
+
+	muteAlert := model.NewAutocompleteData(
+		"mute", "[name|alertID] [--for duration]", "Stop posting alerts for a while")
+	muteAlert.AddTextArgument("Name or AlertId to mute and optional duration like 30m, 2h or 3d", "[name|alertid] [--for duration]", "")
+	alert.AddCommand(muteAlert)
+
+	unmuteAlert := model.NewAutocompleteData(
+		"unmute", "[name|alertID]", "Post alerts of a muted alert again")
+	unmuteAlert.AddTextArgument("Name or AlertId to unmute", "[name|alertid]", "")
+	alert.AddCommand(unmuteAlert)
+
 	deleteAlert := model.NewAutocompleteData(
 		"delete", "", "Remove an alert")
-	deleteAlert.AddTextArgument("AlertId to remove", "[alertid]", "")
+	deleteAlert.AddTextArgument("Name or AlertId to remove", "[name|alertid]", "")
 
 	alert.AddCommand(deleteAlert)
+
+	templateAlert := model.NewAutocompleteData(
+		"template", "[alertID] [template]", "Format posts of the alert with a Go template")
+	templateAlert.AddTextArgument("Name or AlertId and Go template, e.g. {{.SearchName}} on {{field \"host\"}}", "[alertid] [template]", "")
+	alert.AddCommand(templateAlert)
+
+	formatAlert := model.NewAutocompleteData(
+		"format", "[name|alertID] [standard|tokenized|raw]", "Set the payload format of the alert webhook requests")
+	formatAlert.AddTextArgument("Name or AlertId and payload format: standard, tokenized or raw", "[name|alertid] [format]", "")
+	alert.AddCommand(formatAlert)
 	listAlert := model.NewAutocompleteData(
 		"list", "", "List all alerts")
 	alert.AddCommand(listAlert)
@@ -345,22 +946,96 @@ func createAuthCommand() *model.AutocompleteData {
 	auth.AddStaticListArgument("Login to splunk server [server base url] [username/token]", true, flag)
 	auth.AddTextArgument("[server base url]", "Enter the server URL, e.g. https://your-mattermost-url.com", "")
 	auth.AddTextArgument("[username/token]", "Enter the [username/token]", "")
+	auth.AddTextArgument("[--cloud|--enterprise]", "(optional) Server type, Splunk Cloud is detected from *.splunkcloud.com URLs", "")
 
 	return auth
 }
 
+func createChannelCommand() *model.AutocompleteData {
+	channel := model.NewAutocompleteData(
+		"channel", "[command]", "Available commands: server, set-server, unset-server")
+
+	server := model.NewAutocompleteData(
+		"server", "", "Show default server of this channel")
+	channel.AddCommand(server)
+
+	setServer := model.NewAutocompleteData(
+		"set-server", "[server base url]", "Use the server by default for all commands in this channel")
+	setServer.AddTextArgument("Server base URL or host", "[server base url]", "")
+	channel.AddCommand(setServer)
+
+	unsetServer := model.NewAutocompleteData(
+		"unset-server", "", "Stop using a default server in this channel")
+	channel.AddCommand(unsetServer)
+
+	return channel
+}
+
 func createLogCommand() *model.AutocompleteData {
 	log := model.NewAutocompleteData(
-		"log", "[list / logname]", "")
+		"log", "[source / list / export / alias]", "Show events from the log sources of this channel")
+
+	source := model.NewAutocompleteData(
+		"source", "[command]", "Available commands: add, list, remove")
+	addSource := model.NewAutocompleteData(
+		"add", "[alias] [index/sourcetype or SPL]", "Add a log source shared in this channel")
+	addSource.AddTextArgument("Alias followed by index, index/sourcetype or SPL search", "[alias] [index/sourcetype or SPL]", "")
+	source.AddCommand(addSource)
+	listSources := model.NewAutocompleteData(
+		"list", "", "List log sources of this channel")
+	source.AddCommand(listSources)
+	removeSource := model.NewAutocompleteData(
+		"remove", "[alias]", "Remove a log source from this channel")
+	removeSource.AddTextArgument("Alias of the log source", "[alias]", "")
+	source.AddCommand(removeSource)
+	log.AddCommand(source)
+
+	list := model.NewAutocompleteData(
+		"list", "", "List log sources of this channel")
+	log.AddCommand(list)
+
+	export := model.NewAutocompleteData(
+		"export", "[alias]", "Send all events of the last 24 hours to you in a direct message")
+	export.AddTextArgument("Alias of the log source", "[alias]", "")
+	log.AddCommand(export)
 
-	flag := []model.AutocompleteListItem{
-		{HelpText: "List all the log group", Item: "list"},
-	}
+	return log
+}
 
-	log.AddStaticListArgument("List all the log group", false, flag)
-	log.AddTextArgument("[logname]", "Show specific log from server", "")
+func createReportCommand() *model.AutocompleteData {
+	report := model.NewAutocompleteData(
+		"report", "[command]", "Available commands: schedule, list, delete")
 
-	return log
+	schedule := model.NewAutocompleteData(
+		"schedule", "[saved report] --weekly [weekday] [time]", "Post the saved report to this channel on schedule")
+	schedule.AddTextArgument("Name of the saved report followed by --daily [time] or --weekly [weekday] [time]", "[saved report] --weekly monday 9am", "")
+	report.AddCommand(schedule)
+
+	listReports := model.NewAutocompleteData(
+		"list", "", "List reports scheduled in this channel")
+	report.AddCommand(listReports)
+
+	deleteReport := model.NewAutocompleteData(
+		"delete", "[reportID]", "Stop posting the report")
+	deleteReport.AddTextArgument("ReportId to remove", "[reportID]", "")
+	report.AddCommand(deleteReport)
+
+	return report
+}
+
+func createAuditCommand() *model.AutocompleteData {
+	audit := model.NewAutocompleteData(
+		"audit", "[count]", "Show the latest entries of the audit log")
+	audit.AddTextArgument("Number of entries, 20 by default", "[count]", "")
+
+	return audit
+}
+
+func createSetupCommand() *model.AutocompleteData {
+	setup := model.NewAutocompleteData(
+		"setup", "", "Connect to a splunk server and create a test alert subscription")
+
+	return setup
 }
 
 func createHelpCommand() *model.AutocompleteData {
@@ -372,17 +1047,17 @@ func createHelpCommand() *model.AutocompleteData {
 
 // alertSubscriptionMessage creates message for alert subscription
 // returns message text and unique id for alert
-func alertSubscriptionMessage(siteURL, secret string) (string, string) {
+func alertSubscriptionMessage(siteURL, pluginID, secret, name string) (string, string) {
 	id := uuid.New()
+	label := ""
+	if name != "" {
+		label = " " + strings.ToLower(name)
+	}
 	post := fmt.Sprintf(
-		"Added alert\n"+
-			"Copy this [webhook url](%s/plugins/%s%s%s?id=%s&secret=%s) to your splunk alert action.",
-		siteURL,
-		"com.mattermost.plugin-splunk",
-		config.APIPath,
-		api.WebhookEndpoint,
-		id,
-		secret,
+		"Added alert%s\n"+
+			"Copy this [webhook url](%s) to your splunk alert action.",
+		label,
+		api.WebhookURL(siteURL, pluginID, id.String(), secret),
 	)
 	return post, id.String()
 }
@@ -398,6 +1073,8 @@ func isAuthorizedSysAdmin(api plugin.API, userID string) (bool, error) {
 	return true, nil
 }
 
+// parseServerURL keeps the path of the URL for servers behind a reverse proxy,
+// only a trailing /services REST prefix and slash are removed.
 func parseServerURL(u string) (string, error) {
 	ur, err := url.Parse(u)
 	if err != nil {
@@ -407,5 +1084,151 @@ func parseServerURL(u string) (string, error) {
 		return "", errors.New("bad scheme")
 	}
 
-	return ur.Scheme + "://" + ur.Host, err
+	path := strings.TrimSuffix(strings.TrimSuffix(ur.Path, "/"), "/services")
+	return ur.Scheme + "://" + ur.Host + path, err
+}
+
+// parseNameFlag removes --name flag given as --name [name] or --name=[name] from args
+// and returns the name, ok is false if the flag has no value.
+func parseNameFlag(args []string) ([]string, string, bool) {
+	return parseFlag(args, "--name")
+}
+
+// parseFlag removes the flag given as flag [value] or flag=[value] from args
+// and returns its value, ok is false if the flag has no value.
+func parseFlag(args []string, flag string) ([]string, string, bool) {
+	var value string
+	rest := make([]string, 0, len(args))
+	for i := 0; i < len(args); i++ {
+		switch {
+		case args[i] == flag:
+			if i+1 == len(args) {
+				return nil, "", false
+			}
+			value = args[i+1]
+			i++
+		case strings.HasPrefix(args[i], flag+"="):
+			value = strings.TrimPrefix(args[i], flag+"=")
+			if value == "" {
+				return nil, "", false
+			}
+		default:
+			rest = append(rest, args[i])
+		}
+	}
+	return rest, value, true
+}
+
+// parseMuteDuration parses duration given like 90m, 2h or 1h30m, days are given as 3d.
+func parseMuteDuration(s string) (time.Duration, error) {
+	if days := strings.TrimSuffix(s, "d"); days != s {
+		n, err := strconv.Atoi(days)
+		if err != nil {
+			return 0, errors.Errorf("invalid duration %s", s)
+		}
+		return time.Duration(n) * 24 * time.Hour, nil
+	}
+
+	d, err := time.ParseDuration(s)
+	if err != nil {
+		return 0, errors.Errorf("invalid duration %s", s)
+	}
+	return d, nil
+}
+
+// parseServerType removes --cloud or --enterprise flag from args and returns the server type given by it,
+// server type is empty if there is no flag.
+func parseServerType(args []string) ([]string, string) {
+	var serverType string
+	rest := make([]string, 0, len(args))
+	for _, arg := range args {
+		switch arg {
+		case "--cloud":
+			serverType = store.ServerTypeCloud
+		case "--enterprise":
+			serverType = store.ServerTypeEnterprise
+		default:
+			rest = append(rest, arg)
+		}
+	}
+	return rest, serverType
+}
+
+// parseServerAlias parses server given either as URL or as host
+// hosts without scheme are considered to use https.
+func parseServerAlias(alias string) (string, error) {
+	if !strings.Contains(alias, "://") {
+		alias = "https://" + alias
+	}
+	return parseServerURL(alias)
+}
+
+// parseReportSchedule parses arguments of report schedule command
+// like: [saved report name] --weekly [weekday] [time] or [saved report name] --daily [time].
+func parseReportSchedule(args []string) (store.ReportSchedule, error) {
+	var schedule store.ReportSchedule
+	var nameParts []string
+	for len(args) > 0 && !strings.HasPrefix(args[0], "--") {
+		nameParts = append(nameParts, args[0])
+		args = args[1:]
+	}
+	schedule.Report = strings.Join(nameParts, " ")
+	if schedule.Report == "" {
+		return schedule, errors.New("Please provide name of the saved report")
+	}
+
+	var timeArg string
+	switch {
+	case len(args) == 2 && args[0] == "--daily":
+		schedule.Interval = store.ReportDaily
+		timeArg = args[1]
+	case len(args) == 3 && args[0] == "--weekly":
+		weekday, ok := weekdays[strings.ToLower(args[1])]
+		if !ok {
+			return schedule, errors.Errorf("Unknown weekday %q", args[1])
+		}
+		schedule.Interval = store.ReportWeekly
+		schedule.Weekday = weekday
+		timeArg = args[2]
+	default:
+		return schedule, errors.New("Please provide schedule like so: --daily [time] or --weekly [weekday] [time]")
+	}
+
+	var err error
+	schedule.Hour, schedule.Minute, err = parseTimeOfDay(timeArg)
+	return schedule, err
+}
+
+var weekdays = map[string]time.Weekday{
+	"sunday":    time.Sunday,
+	"monday":    time.Monday,
+	"tuesday":   time.Tuesday,
+	"wednesday": time.Wednesday,
+	"thursday":  time.Thursday,
+	"friday":    time.Friday,
+	"saturday":  time.Saturday,
+}
+
+// parseTimeOfDay parses time like 9am, 9:30pm or 21:30.
+func parseTimeOfDay(s string) (int, int, error) {
+	for _, layout := range []string{"3pm", "3:04pm", "15:04", "15"} {
+		t, err := time.Parse(layout, strings.ToLower(s))
+		if err == nil {
+			return t.Hour(), t.Minute(), nil
+		}
+	}
+	return 0, 0, errors.Errorf("Unknown time %q, use formats like 9am, 9:30pm or 21:30", s)
+}
+
+// textAfterFields returns the rest of s after skipping n whitespace separated fields.
+func textAfterFields(s string, n int) string {
+	for i := 0; i < n; i++ {
+		s = strings.TrimLeftFunc(s, unicode.IsSpace)
+		idx := strings.IndexFunc(s, unicode.IsSpace)
+		if idx == -1 {
+			return ""
+		}
+		s = s[idx:]
+	}
+	return strings.TrimSpace(s)
 }
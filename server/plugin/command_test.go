@@ -1,6 +1,14 @@
 package plugin
 
-import "testing"
+import (
+	"testing"
+	"time"
+
+	"github.com/mattermost/mattermost-server/v6/model"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/mattermost/mattermost-plugin-splunk/server/store"
+)
 
 func Test_parseServerURL(t *testing.T) {
 	tests := []struct {
@@ -9,8 +17,10 @@ func Test_parseServerURL(t *testing.T) {
 		want    string
 		wantErr bool
 	}{
-		{name: "default", u: "https://gobyexample.com:8080/url-parsing", want: "https://gobyexample.com:8080", wantErr: false},
-		{name: "no port", u: "http://gobyexample.com/url-parsing:8080", want: "http://gobyexample.com", wantErr: false},
+		{name: "default", u: "https://gobyexample.com:8080/url-parsing", want: "https://gobyexample.com:8080/url-parsing", wantErr: false},
+		{name: "no port", u: "http://gobyexample.com/url-parsing:8080", want: "http://gobyexample.com/url-parsing:8080", wantErr: false},
+		{name: "no path", u: "https://gobyexample.com:8080/", want: "https://gobyexample.com:8080", wantErr: false},
+		{name: "services", u: "https://proxy.example.com/splunk/services/", want: "https://proxy.example.com/splunk", wantErr: false},
 		{name: "bad url", u: "mail://gg.com/url-parsing:8080", want: "", wantErr: true},
 	}
 
@@ -27,3 +37,130 @@ func Test_parseServerURL(t *testing.T) {
 		})
 	}
 }
+
+func Test_parseServerAlias(t *testing.T) {
+	tests := []struct {
+		name    string
+		alias   string
+		want    string
+		wantErr bool
+	}{
+		{name: "url", alias: "http://splunk.example.com:8089/services", want: "http://splunk.example.com:8089", wantErr: false},
+		{name: "host", alias: "splunk.example.com:8089", want: "https://splunk.example.com:8089", wantErr: false},
+		{name: "bad scheme", alias: "ftp://splunk.example.com", want: "", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := parseServerAlias(tt.alias)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("parseServerAlias() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if got != tt.want {
+				t.Errorf("parseServerAlias() got = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func Test_parseReportSchedule(t *testing.T) {
+	tests := []struct {
+		name    string
+		args    []string
+		want    store.ReportSchedule
+		wantErr bool
+	}{
+		{
+			name: "weekly",
+			args: []string{"Errors", "by", "host", "--weekly", "Monday", "9am"},
+			want: store.ReportSchedule{Report: "Errors by host", Interval: store.ReportWeekly, Weekday: time.Monday, Hour: 9},
+		},
+		{
+			name: "daily",
+			args: []string{"errors", "--daily", "21:30"},
+			want: store.ReportSchedule{Report: "errors", Interval: store.ReportDaily, Hour: 21, Minute: 30},
+		},
+		{
+			name: "pm",
+			args: []string{"errors", "--daily", "9:15pm"},
+			want: store.ReportSchedule{Report: "errors", Interval: store.ReportDaily, Hour: 21, Minute: 15},
+		},
+		{name: "no name", args: []string{"--daily", "9am"}, wantErr: true},
+		{name: "no schedule", args: []string{"errors"}, wantErr: true},
+		{name: "bad weekday", args: []string{"errors", "--weekly", "someday", "9am"}, wantErr: true},
+		{name: "bad time", args: []string{"errors", "--daily", "noon"}, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := parseReportSchedule(tt.args)
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func Test_textAfterFields(t *testing.T) {
+	assert.Equal(t, "{{.Sid}}\n  {{.App}}", textAfterFields("/splunk alert template id {{.Sid}}\n  {{.App}} ", 4))
+	assert.Equal(t, "", textAfterFields("/splunk alert template id", 4))
+	assert.Equal(t, "", textAfterFields("/splunk alert", 4))
+}
+
+func Test_parseServerType(t *testing.T) {
+	args, serverType := parseServerType([]string{"https://acme.example.com", "--cloud", "admin/token"})
+	assert.Equal(t, []string{"https://acme.example.com", "admin/token"}, args)
+	assert.Equal(t, store.ServerTypeCloud, serverType)
+
+	args, serverType = parseServerType([]string{"https://splunk.example.com", "admin"})
+	assert.Equal(t, []string{"https://splunk.example.com", "admin"}, args)
+	assert.Equal(t, "", serverType)
+}
+
+func Test_parseNameFlag(t *testing.T) {
+	args, name, ok := parseNameFlag([]string{"--name", "cpu-alerts"})
+	assert.True(t, ok)
+	assert.Empty(t, args)
+	assert.Equal(t, "cpu-alerts", name)
+
+	args, name, ok = parseNameFlag([]string{"1234", "--name=disk"})
+	assert.True(t, ok)
+	assert.Equal(t, []string{"1234"}, args)
+	assert.Equal(t, "disk", name)
+
+	_, _, ok = parseNameFlag([]string{"1234", "--name"})
+	assert.False(t, ok)
+}
+
+func Test_redactCredentials(t *testing.T) {
+	assert.Equal(t,
+		[]string{"https://splunk.example.com:8089", "admin/***", "--cloud"},
+		redactCredentials([]string{"https://splunk.example.com:8089", "admin/secret-token", "--cloud"}))
+	assert.Equal(t, []string{"admin"}, redactCredentials([]string{"admin"}))
+}
+
+func Test_parseMuteDuration(t *testing.T) {
+	d, err := parseMuteDuration("2h")
+	assert.NoError(t, err)
+	assert.Equal(t, 2*time.Hour, d)
+
+	d, err = parseMuteDuration("3d")
+	assert.NoError(t, err)
+	assert.Equal(t, 72*time.Hour, d)
+
+	_, err = parseMuteDuration("soon")
+	assert.Error(t, err)
+}
+
+func Test_addLogSourceReservedAlias(t *testing.T) {
+	c := &CommandHandler{args: &model.CommandArgs{Command: "/splunk log source add List main"}}
+	for _, alias := range []string{"list", "List", "export", "source"} {
+		got, err := c.addLogSource(alias, "main")
+		assert.NoError(t, err)
+		assert.Equal(t, "Alias "+alias+" is a log subcommand, please use another alias", got)
+	}
+}
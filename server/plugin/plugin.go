@@ -15,6 +15,7 @@ import (
 
 	"github.com/mattermost/mattermost-plugin-splunk/server/api"
 	"github.com/mattermost/mattermost-plugin-splunk/server/config"
+	"github.com/mattermost/mattermost-plugin-splunk/server/jobs"
 	"github.com/mattermost/mattermost-plugin-splunk/server/splunk"
 	"github.com/mattermost/mattermost-plugin-splunk/server/store"
 
@@ -28,6 +29,8 @@ type Plugin struct {
 
 	sp splunk.Splunk
 
+	jobs *jobs.Runner
+
 	// configurationLock synchronizes access to the configuration.
 	configurationLock *sync.RWMutex
 
@@ -53,7 +56,7 @@ func NewWithStore(store store.Store, conf *config.Config) *Plugin {
 	}
 
 	p.sp = splunk.New(p, store)
-	p.httpHandler = api.NewHTTPHandler(p.sp, conf)
+	p.httpHandler = api.NewHTTPHandler(p.sp)
 	return p
 }
 
@@ -65,7 +68,7 @@ func NewWithSplunk(sp splunk.Splunk, conf *config.Config) *Plugin {
 		sp:                sp,
 	}
 
-	p.httpHandler = api.NewHTTPHandler(p.sp, conf)
+	p.httpHandler = api.NewHTTPHandler(p.sp)
 	return p
 }
 
@@ -76,7 +79,7 @@ func (p *Plugin) OnActivate() error {
 	if p.sp == nil {
 		pluginStore := store.NewPluginStore(p)
 		p.sp = splunk.New(p, pluginStore)
-		p.httpHandler = api.NewHTTPHandler(p.sp, p.GetConfiguration())
+		p.httpHandler = api.NewHTTPHandler(p.sp)
 	}
 
 	cmd, err := p.GetSlashCommand()
@@ -100,9 +103,48 @@ func (p *Plugin) OnActivate() error {
 	}
 	p.sp.AddBotUser(botID)
 
+	p.jobs = jobs.NewRunner(p.API)
+	if err = p.jobs.Exclusive("migrate-servers", p.sp.MigrateServers); err != nil {
+		p.API.LogWarn("failed to migrate splunk servers", "error", err.Error())
+	}
+	// every plugin instance is activated, onboarding is serialized so admins get a single message
+	if err = p.jobs.Exclusive("onboarding", p.sp.OnboardSysAdmins); err != nil {
+		p.API.LogWarn("failed to onboard system admins", "error", err.Error())
+	}
+
+	for _, job := range p.sp.Jobs() {
+		if err = p.jobs.Register(job); err != nil {
+			return err
+		}
+	}
+	if err = p.jobs.Start(); err != nil {
+		return errors.Wrap(err, "failed to start jobs")
+	}
+
 	return nil
 }
 
+// OnDeactivate called when plugin is deactivated
+func (p *Plugin) OnDeactivate() error {
+	if p.jobs != nil {
+		p.jobs.Close()
+	}
+	if p.sp != nil {
+		p.sp.Close()
+	}
+	return nil
+}
+
+// UserHasLeftChannel hook reports alert subscriptions the user created in the channel
+func (p *Plugin) UserHasLeftChannel(_ *mattermostPlugin.Context, channelMember *model.ChannelMember, _ *model.User) {
+	p.sp.UserLeftChannel(channelMember.UserId, channelMember.ChannelId)
+}
+
+// UserHasLeftTeam hook reports alert subscriptions the user created in the team
+func (p *Plugin) UserHasLeftTeam(_ *mattermostPlugin.Context, teamMember *model.TeamMember, _ *model.User) {
+	p.sp.UserLeftTeam(teamMember.UserId, teamMember.TeamId)
+}
+
 // ExecuteCommand hook is called when slash command is submitted
 func (p *Plugin) ExecuteCommand(_ *mattermostPlugin.Context, commandArgs *model.CommandArgs) (*model.CommandResponse, *model.AppError) {
 	mattermostUserID := commandArgs.UserId
@@ -129,6 +171,10 @@ func (p *Plugin) ExecuteCommand(_ *mattermostPlugin.Context, commandArgs *model.
 }
 
 func (p *Plugin) sendEphemeralResponse(args *model.CommandArgs, text string) *model.CommandResponse {
+	// commands opening dialogs have nothing to respond with
+	if text == "" {
+		return &model.CommandResponse{}
+	}
 	p.API.SendEphemeralPost(args.UserId, &model.Post{
 		UserId:    p.sp.BotUser(),
 		ChannelId: args.ChannelId,
@@ -146,8 +192,22 @@ func (p *Plugin) OnConfigurationChange() error {
 		return errors.Wrap(err, "failed to load plugin Config")
 	}
 
+	if err := configuration.IsValid(); err != nil {
+		return errors.Wrap(err, "invalid plugin Config")
+	}
+
+	// plugin ID and version come from the manifest, they are not part of the settings
+	previous := p.GetConfiguration()
+	configuration.PluginID, configuration.PluginVersion = previous.PluginID, previous.PluginVersion
 	p.setConfiguration(configuration)
 
+	if p.sp != nil && previous.Secret != "" && previous.Secret != configuration.Secret {
+		p.sp.Audit(store.AuditEntry{
+			Action:  "secret/rotate",
+			Details: "webhook secret changed, webhook URLs of alert subscriptions have to be updated",
+			Result:  "success",
+		})
+	}
 	return nil
 }
 
@@ -19,6 +19,50 @@ func (p *Plugin) CreatePost(post *model.Post) (*model.Post, error) {
 	return post, nil
 }
 
+// GetPost gets a post by its ID
+func (p *Plugin) GetPost(postID string) (*model.Post, error) {
+	post, err := p.API.GetPost(postID)
+	if err != nil {
+		return nil, errors.Wrap(err, "error while retrieving post")
+	}
+	return post, nil
+}
+
+// UpdatePost updates an existing post
+func (p *Plugin) UpdatePost(post *model.Post) (*model.Post, error) {
+	post, err := p.API.UpdatePost(post)
+	if err != nil {
+		return nil, errors.Wrap(err, "error while updating post")
+	}
+	return post, nil
+}
+
+// UploadFile uploads a file to a channel to be later attached to a post
+func (p *Plugin) UploadFile(data []byte, channelID string, filename string) (*model.FileInfo, error) {
+	info, err := p.API.UploadFile(data, channelID, filename)
+	if err != nil {
+		return nil, errors.Wrap(err, "error while uploading file")
+	}
+	return info, nil
+}
+
+// OpenInteractiveDialog opens an interactive dialog on a user's client
+func (p *Plugin) OpenInteractiveDialog(dialog model.OpenDialogRequest) error {
+	if err := p.API.OpenInteractiveDialog(dialog); err != nil {
+		return errors.Wrap(err, "error while opening dialog")
+	}
+	return nil
+}
+
+// GetUsers gets paginated user list
+func (p *Plugin) GetUsers(options *model.UserGetOptions) ([]*model.User, error) {
+	users, err := p.API.GetUsers(options)
+	if err != nil {
+		return []*model.User{}, errors.Wrap(err, "error while retrieving user list")
+	}
+	return users, nil
+}
+
 // GetUsersInChannel gets paginated user list for channel
 func (p *Plugin) GetUsersInChannel(channelID, sortBy string, page, perPage int) ([]*model.User, error) {
 	users, err := p.API.GetUsersInChannel(channelID, sortBy, page, perPage)
@@ -28,11 +72,84 @@ func (p *Plugin) GetUsersInChannel(channelID, sortBy string, page, perPage int)
 	return users, nil
 }
 
+// GetUser gets a user by their ID
+func (p *Plugin) GetUser(userID string) (*model.User, error) {
+	user, err := p.API.GetUser(userID)
+	if err != nil {
+		return nil, errors.Wrap(err, "error while retrieving user")
+	}
+	return user, nil
+}
+
+// GetUserByUsername gets a user by their username
+func (p *Plugin) GetUserByUsername(username string) (*model.User, error) {
+	user, err := p.API.GetUserByUsername(username)
+	if err != nil {
+		return nil, errors.Wrap(err, "error while retrieving user")
+	}
+	return user, nil
+}
+
+// GetChannel gets a channel by its ID
+func (p *Plugin) GetChannel(channelID string) (*model.Channel, error) {
+	channel, err := p.API.GetChannel(channelID)
+	if err != nil {
+		return nil, errors.Wrap(err, "error while retrieving channel")
+	}
+	return channel, nil
+}
+
+// GetDirectChannel gets a direct message channel of two users, the channel is created if it doesn't exist
+func (p *Plugin) GetDirectChannel(userID1, userID2 string) (*model.Channel, error) {
+	channel, err := p.API.GetDirectChannel(userID1, userID2)
+	if err != nil {
+		return nil, errors.Wrap(err, "error while retrieving direct channel")
+	}
+	return channel, nil
+}
+
+// GetChannelByNameForTeamName gets a channel by its name, given a team name
+func (p *Plugin) GetChannelByNameForTeamName(teamName, channelName string) (*model.Channel, error) {
+	channel, err := p.API.GetChannelByNameForTeamName(teamName, channelName, false)
+	if err != nil {
+		return nil, errors.Wrap(err, "error while retrieving channel")
+	}
+	return channel, nil
+}
+
+// GetTeam gets a team by its ID
+func (p *Plugin) GetTeam(teamID string) (*model.Team, error) {
+	team, err := p.API.GetTeam(teamID)
+	if err != nil {
+		return nil, errors.Wrap(err, "error while retrieving team")
+	}
+	return team, nil
+}
+
+// HasPermissionTo checks if the user has the permission at system scope
+func (p *Plugin) HasPermissionTo(userID string, permission *model.Permission) bool {
+	return p.API.HasPermissionTo(userID, permission)
+}
+
+// HasPermissionToChannel checks if the user has the permission in the channel
+func (p *Plugin) HasPermissionToChannel(userID, channelID string, permission *model.Permission) bool {
+	return p.API.HasPermissionToChannel(userID, channelID, permission)
+}
+
 // PublishWebSocketEvent sends broadcast
 func (p *Plugin) PublishWebSocketEvent(event string, payload map[string]interface{}, broadcast *model.WebsocketBroadcast) {
 	p.API.PublishWebSocketEvent(event, payload, broadcast)
 }
 
+// GetSiteURL returns the site URL of the Mattermost server
+func (p *Plugin) GetSiteURL() string {
+	siteURL := p.API.GetConfig().ServiceSettings.SiteURL
+	if siteURL == nil {
+		return ""
+	}
+	return *siteURL
+}
+
 // KVGet retrieves a value based on the key, unique per plugin. Returns nil for non-existent keys.
 func (p *Plugin) KVGet(key string) ([]byte, *model.AppError) {
 	return p.API.KVGet(key)
@@ -43,11 +160,26 @@ func (p *Plugin) KVSet(key string, value []byte) *model.AppError {
 	return p.API.KVSet(key, value)
 }
 
+// KVSetWithExpiry stores a key-value pair with an expiry time, unique per plugin.
+func (p *Plugin) KVSetWithExpiry(key string, value []byte, expireInSeconds int64) *model.AppError {
+	return p.API.KVSetWithExpiry(key, value, expireInSeconds)
+}
+
+// KVSetWithOptions stores a key-value pair, unique per plugin, according to the given options.
+func (p *Plugin) KVSetWithOptions(key string, value []byte, options model.PluginKVSetOptions) (bool, *model.AppError) {
+	return p.API.KVSetWithOptions(key, value, options)
+}
+
 // KVDelete removes a key-value pair, unique per plugin. Returns nil for non-existent keys.
 func (p *Plugin) KVDelete(key string) *model.AppError {
 	return p.API.KVDelete(key)
 }
 
+// KVList lists all keys for a plugin.
+func (p *Plugin) KVList(page, perPage int) ([]string, *model.AppError) {
+	return p.API.KVList(page, perPage)
+}
+
 // LogWarn writes a log message to the Mattermost server log file.
 func (p *Plugin) LogWarn(msg string, keyValuePairs ...interface{}) {
 	p.API.LogWarn(msg, keyValuePairs)
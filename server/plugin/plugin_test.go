@@ -0,0 +1,27 @@
+package plugin
+
+import (
+	"testing"
+
+	"github.com/mattermost/mattermost-server/v6/plugin/plugintest"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+
+	"github.com/mattermost/mattermost-plugin-splunk/server/config"
+)
+
+func TestPlugin_OnConfigurationChangeKeepsManifestFields(t *testing.T) {
+	api := &plugintest.API{}
+	api.On("LoadPluginConfiguration", mock.AnythingOfType("*config.Config")).Run(func(args mock.Arguments) {
+		args.Get(0).(*config.Config).Secret = "secret"
+	}).Return(nil)
+
+	p := NewWithConfig(&config.Config{PluginID: "com.mattermost.plugin-splunk", PluginVersion: "0.1.0"})
+	p.SetAPI(api)
+	assert.NoError(t, p.OnConfigurationChange())
+
+	conf := p.GetConfiguration()
+	assert.Equal(t, "com.mattermost.plugin-splunk", conf.PluginID)
+	assert.Equal(t, "0.1.0", conf.PluginVersion)
+	assert.Equal(t, "secret", conf.Secret)
+}
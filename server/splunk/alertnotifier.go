@@ -1,14 +1,37 @@
 package splunk
 
 import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/xml"
 	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
 
 	"github.com/mattermost/mattermost-server/v6/model"
 	"github.com/pkg/errors"
+
+	"github.com/mattermost/mattermost-plugin-splunk/server/store"
 )
 
-func (s *splunk) AddAlert(channelID string, alertID string) error {
-	err := s.Store.CreateAlert(channelID, alertID)
+// alertDeliveryTTL is how long a delivered alert is remembered.
+// Splunk retries and replayed webhook requests within this period are ignored.
+const alertDeliveryTTL = 10 * time.Minute
+
+// alertResultsTimeout limits fetching of the result preview, so a slow splunk doesn't hold up alert posts
+const alertResultsTimeout = 10 * time.Second
+
+func (s *splunk) AddAlert(alert store.Alert) error {
+	if alert.Name != "" {
+		if err := s.checkAlertName(alert.ChannelID, alert.Name); err != nil {
+			return err
+		}
+	}
+
+	err := s.Store.CreateAlert(alert)
 	if err != nil {
 		return errors.Wrap(err, "error in storing alert")
 	}
@@ -17,41 +40,309 @@ func (s *splunk) AddAlert(channelID string, alertID string) error {
 }
 
 func (s *splunk) Notify(alertID string, payload AlertActionWHPayload) error {
-	channelID, err := s.Store.GetChannelIDForAlert(alertID)
+	alert, err := s.Store.GetAlert(alertID)
 	if err != nil {
 		return errors.Wrap(err, "error while getting subscription")
 	}
 
-	if channelID == "" {
+	if alert.ChannelID == "" {
+		return ErrAlertNotFound
+	}
+	if alert.DisabledReason != "" {
+		return ErrAlertDisabled
+	}
+
+	// alerts without a search job can't be told apart from the next firing, they are never deduplicated
+	fingerprint := alertFingerprint(alertID, payload)
+	if s.isDelivered(fingerprint) {
+		s.LogDebug("skipping already delivered alert", "alertID", alertID, "sid", payload.Sid)
+		return nil
+	}
+
+	if isMuted(alert) {
+		s.LogDebug("skipping alert of muted subscription", "alertID", alertID, "sid", payload.Sid)
+		s.countSuppressed(alertID)
+		s.rememberDelivery(fingerprint)
 		return nil
 	}
 
-	_, err = s.CreatePost(&model.Post{
-		UserId:    s.BotUser(),
-		ChannelId: channelID,
-		Message:   fmt.Sprintf("New alert action received %s", payload.ResultsLink),
-	})
+	if payload.SearchName != "" && (alert.SearchName != payload.SearchName || alert.App != payload.App || alert.Owner != payload.Owner) {
+		alert.SearchName, alert.App, alert.Owner = payload.SearchName, payload.App, payload.Owner
+		if err = s.Store.UpdateAlert(alert); err != nil {
+			s.LogWarn("failed to store alert search", "alertID", alertID, "error", err.Error())
+		}
+	}
+
+	message := s.alertMessage(alert, payload)
+
+	var postErr error
+	for _, channelID := range s.alertChannels(alert, payload.Severity()) {
+		// retries of a partly delivered alert are posted only to the channels which failed
+		channelKey := channelDeliveryKey(fingerprint, channelID)
+		if s.isDelivered(channelKey) {
+			continue
+		}
+
+		post := &model.Post{
+			UserId:    s.BotUser(),
+			ChannelId: channelID,
+			Message:   message,
+		}
+		model.ParseSlackAttachment(post, []*model.SlackAttachment{s.alertAttachment(alertID, payload.SearchName, "")})
+		if _, err = s.CreatePost(post); err != nil {
+			s.ReportDeliveryError(DeliveryError{
+				CorrelationID: model.NewId(),
+				Reason:        "Failed to post alert",
+				AlertID:       alertID,
+				ChannelID:     channelID,
+				Err:           err,
+			})
+			if postErr == nil {
+				postErr = err
+			}
+			continue
+		}
+		s.rememberDelivery(channelKey)
+	}
+	if postErr != nil {
+		return errors.Wrap(postErr, "error creating post to notify channel for alert")
+	}
+
+	s.rememberDelivery(fingerprint)
+	return nil
+}
+
+// isDelivered checks if the delivery with the key was remembered, empty key is never delivered.
+func (s *splunk) isDelivered(key string) bool {
+	if key == "" {
+		return false
+	}
+	var delivered bool
+	found, err := s.Store.LoadEphemeral(key, &delivered)
+	if err != nil {
+		s.LogWarn("failed to check alert delivery", "error", err.Error())
+		return false
+	}
+	return found
+}
+
+// rememberDelivery remembers the delivery with the key for alertDeliveryTTL, empty key is not remembered.
+func (s *splunk) rememberDelivery(key string) {
+	if key == "" {
+		return
+	}
+	if err := s.Store.StoreEphemeral(key, true, alertDeliveryTTL); err != nil {
+		s.LogWarn("failed to remember alert delivery", "error", err.Error())
+	}
+}
+
+// alertMessage formats alert post message with the subscription template
+// falling back to the default format if there is no template or it fails.
+func (s *splunk) alertMessage(alert store.Alert, payload AlertActionWHPayload) string {
+	preview := s.alertPreview(alert, payload)
+	if alert.Template != "" {
+		message, err := renderAlertTemplate(alert.Template, alertTemplateData{
+			AlertActionWHPayload: payload,
+			AlertID:              alert.ID,
+			Name:                 alert.Name,
+			Preview:              preview,
+		})
+		if err == nil {
+			return message
+		}
+		s.LogWarn("failed to render alert template", "alertID", alert.ID, "error", err.Error())
+	}
+
+	message := "New alert action received"
+	if payload.ResultsLink != "" {
+		message += " " + payload.ResultsLink
+	}
+	if alert.Name != "" {
+		message = fmt.Sprintf("**%s**: %s", alert.Name, message)
+	}
+	if payload.Raw != "" {
+		message += "\n\n" + rawPayloadBlock(payload.Raw)
+	}
+	if preview != "" {
+		message += "\n\n" + preview
+	}
+	return message
+}
+
+// SetAlertTemplate sets message template of the alert subscribed in the channel given by its name or ID
+// empty template resets alert to the default format.
+func (s *splunk) SetAlertTemplate(channelID string, ref string, text string) error {
+	if err := ParseAlertTemplate(text); err != nil {
+		return errors.Wrap(err, "invalid template")
+	}
+
+	alert, err := s.findAlert(channelID, ref)
 	if err != nil {
-		return errors.Wrap(err, "error creating post to notify channel for alert")
+		return err
 	}
 
+	alert.Template = text
+	if err = s.Store.UpdateAlert(alert); err != nil {
+		return errors.Wrap(err, "error in storing alert")
+	}
 	return nil
 }
 
-func (s *splunk) ListAlert(channelID string) ([]string, error) {
-	alerts, err := s.Store.GetChannelAlertIDs(channelID)
+// alertPreview renders first result rows of the search which triggered the alert.
+// Returns empty string if preview is disabled or results can't be fetched.
+func (s *splunk) alertPreview(alert store.Alert, payload AlertActionWHPayload) string {
+	rows := s.GetConfiguration().AlertPreviewRows
+	if rows <= 0 || payload.Sid == "" {
+		return ""
+	}
+
+	results, err := s.alertResults(alert, payload.Sid, rows)
 	if err != nil {
-		return alerts, errors.Wrap(err, "error in listing alerts")
+		s.LogWarn("failed to fetch alert results", "alertID", alert.ID, "sid", payload.Sid, "error", err.Error())
+		return ""
 	}
+	return results.MarkdownTable()
+}
 
+// alertResults fetches results of the search job with subscription creator's credentials
+// falling back to the service account.
+func (s *splunk) alertResults(alert store.Alert, sid string, count int) (LogResults, error) {
+	var user store.SplunkUser
+	if alert.CreatorID != "" {
+		if u, err := s.Store.User(alert.CreatorID, alert.Server, alert.UserName); err == nil {
+			user = u
+		}
+	}
+	if user.Token == "" {
+		conf := s.GetConfiguration()
+		server, serverType, err := ServerURL(conf.ServiceAccountServer, "", conf.ManagementPort)
+		if err != nil {
+			return LogResults{}, errors.Wrap(err, "bad service account server")
+		}
+		user = store.SplunkUser{
+			Server:     server,
+			Token:      conf.ServiceAccountToken,
+			Type:       serverType,
+			AuthScheme: tokenAuthScheme(conf.ServiceAccountToken),
+		}
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), alertResultsTimeout)
+	defer cancel()
+	endpoint := fmt.Sprintf("%s/%s/results?count=%d", LogsEndpoint, url.PathEscape(sid), count)
+	resp, err := s.doHTTPRequestWithContext(ctx, user, http.MethodGet, endpoint, nil)
+	if err != nil {
+		return LogResults{}, errors.Wrap(err, "no data for alert results")
+	}
+	defer func() { _ = resp.Body.Close() }()
+
+	var results LogResults
+	if err = xml.NewDecoder(resp.Body).Decode(&results); err != nil {
+		return LogResults{}, errors.Wrap(err, "unexpected response")
+	}
+	if len(results.Results) > count {
+		results.Results = results.Results[:count]
+	}
+	return results, nil
+}
+
+func (s *splunk) ListAlert(channelID string) ([]store.Alert, error) {
+	ids, err := s.Store.GetChannelAlertIDs(channelID)
+	if err != nil {
+		return nil, errors.Wrap(err, "error in listing alerts")
+	}
+
+	var alerts []store.Alert
+	for _, id := range ids {
+		alert, getErr := s.Store.GetAlert(id)
+		if getErr != nil {
+			return nil, errors.Wrap(getErr, "error in listing alerts")
+		}
+		alerts = append(alerts, alert)
+	}
 	return alerts, nil
 }
 
-func (s *splunk) DeleteAlert(channelID string, alertID string) error {
-	err := s.Store.DeleteChannelAlert(channelID, alertID)
+// DeleteAlert removes alert subscription of the channel given by its name or ID.
+func (s *splunk) DeleteAlert(channelID string, ref string) error {
+	alert, err := s.findAlert(channelID, ref)
+	if err != nil {
+		return err
+	}
+
+	err = s.Store.DeleteChannelAlert(channelID, alert.ID)
 	if err != nil {
 		return errors.Wrap(err, "error in deleting alert")
 	}
 
 	return nil
 }
+
+// RenameAlert changes name of the alert subscription of the channel given by its name or ID.
+func (s *splunk) RenameAlert(channelID string, ref string, name string) error {
+	alert, err := s.findAlert(channelID, ref)
+	if err != nil {
+		return err
+	}
+	if name = strings.ToLower(name); name == alert.Name {
+		return nil
+	}
+	if err = s.checkAlertName(channelID, name); err != nil {
+		return err
+	}
+
+	alert.Name = name
+	if err = s.Store.UpdateAlert(alert); err != nil {
+		return errors.Wrap(err, "error in storing alert")
+	}
+	return nil
+}
+
+// findAlert returns alert subscription of the channel with the given name or ID.
+func (s *splunk) findAlert(channelID string, ref string) (store.Alert, error) {
+	alerts, err := s.ListAlert(channelID)
+	if err != nil {
+		return store.Alert{}, err
+	}
+	for _, alert := range alerts {
+		if alert.ID == ref || (alert.Name != "" && alert.Name == strings.ToLower(ref)) {
+			return alert, nil
+		}
+	}
+	return store.Alert{}, errors.Errorf("alert %s was not found in this channel", ref)
+}
+
+// checkAlertName checks that the name is valid and not used by other subscriptions of the channel.
+func (s *splunk) checkAlertName(channelID string, name string) error {
+	if !namePattern.MatchString(name) {
+		return errors.New("alert name can only have lowercase letters, digits, - and _")
+	}
+	alerts, err := s.ListAlert(channelID)
+	if err != nil {
+		return err
+	}
+	for _, alert := range alerts {
+		if alert.Name == name {
+			return errors.Errorf("alert %s already exists in this channel", name)
+		}
+	}
+	return nil
+}
+
+// alertFingerprint returns key which identifies single delivery of the alert by the search job which fired it
+// and the time of the result. Returns empty key if the payload has no search job.
+func alertFingerprint(alertID string, payload AlertActionWHPayload) string {
+	if payload.Sid == "" {
+		return ""
+	}
+	sum := sha256.Sum256([]byte(strings.Join([]string{alertID, payload.Sid, payload.Result.Field("_time")}, "\x00")))
+	return "alertfp_" + hex.EncodeToString(sum[:])
+}
+
+// channelDeliveryKey remembers that the alert with the fingerprint was posted to the channel.
+func channelDeliveryKey(fingerprint string, channelID string) string {
+	if fingerprint == "" {
+		return ""
+	}
+	return fingerprint + "_" + channelID
+}
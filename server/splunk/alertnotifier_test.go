@@ -0,0 +1,256 @@
+package splunk
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/mattermost/mattermost-server/v6/model"
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/mattermost/mattermost-plugin-splunk/server/config"
+	"github.com/mattermost/mattermost-plugin-splunk/server/store"
+	"github.com/mattermost/mattermost-plugin-splunk/server/store/mock"
+)
+
+type fakePluginAPI struct {
+	PluginAPI
+	posts  []*model.Post
+	config *config.Config
+}
+
+func (f *fakePluginAPI) CreatePost(post *model.Post) (*model.Post, error) {
+	f.posts = append(f.posts, post)
+	return post, nil
+}
+
+func (f *fakePluginAPI) GetConfiguration() *config.Config {
+	if f.config != nil {
+		return f.config
+	}
+	return &config.Config{PluginID: "com.mattermost.plugin-splunk"}
+}
+
+func (f *fakePluginAPI) LogDebug(_ string, _ ...interface{}) {}
+
+func (f *fakePluginAPI) LogInfo(_ string, _ ...interface{}) {}
+
+func (f *fakePluginAPI) LogWarn(_ string, _ ...interface{}) {}
+
+func (f *fakePluginAPI) LogError(_ string, _ ...interface{}) {}
+
+func Test_splunk_NotifyDeduplicatesDeliveries(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	is := assert.New(t)
+
+	payload := AlertActionWHPayload{Sid: "scheduler__admin__search__RMD5", ResultsLink: "http://splunk/results"}
+	fingerprint := alertFingerprint("alert", payload)
+
+	m := mock.NewMockStore(ctrl)
+	m.EXPECT().GetAlert("alert").Return(store.Alert{ID: "alert", ChannelID: "channel"}, nil).Times(2)
+	gomock.InOrder(
+		m.EXPECT().LoadEphemeral(fingerprint, gomock.Any()).Return(false, nil),
+		m.EXPECT().LoadEphemeral(channelDeliveryKey(fingerprint, "channel"), gomock.Any()).Return(false, nil),
+		m.EXPECT().StoreEphemeral(channelDeliveryKey(fingerprint, "channel"), true, alertDeliveryTTL).Return(nil),
+		m.EXPECT().StoreEphemeral(fingerprint, true, alertDeliveryTTL).Return(nil),
+		m.EXPECT().LoadEphemeral(fingerprint, gomock.Any()).Return(true, nil),
+	)
+
+	api := &fakePluginAPI{}
+	s := newSplunk(api, m)
+	is.NoError(s.Notify("alert", payload))
+	is.NoError(s.Notify("alert", payload))
+	is.Len(api.posts, 1)
+	is.Equal("channel", api.posts[0].ChannelId)
+}
+
+func Test_splunk_NotifyAddsRerunButton(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	is := assert.New(t)
+
+	payload := AlertActionWHPayload{SearchName: "Errors", ResultsLink: "http://splunk/results"}
+	m := mock.NewMockStore(ctrl)
+	m.EXPECT().GetAlert("alert").Return(store.Alert{ID: "alert", ChannelID: "channel", SearchName: "Errors"}, nil)
+
+	api := &fakePluginAPI{}
+	s := newSplunk(api, m)
+	is.NoError(s.Notify("alert", payload))
+	is.Len(api.posts, 1)
+
+	attachments := api.posts[0].Attachments()
+	is.Len(attachments, 1)
+	is.Len(attachments[0].Actions, 2)
+	action := attachments[0].Actions[0]
+	is.Equal("Re-run search", action.Name)
+	is.Equal("/plugins/com.mattermost.plugin-splunk/api/v1"+RerunEndpoint, action.Integration.URL)
+	is.Equal("Errors", action.Integration.Context[rerunContextSearchName])
+	is.Equal("alert", action.Integration.Context[rerunContextAlertID])
+	is.Equal("/plugins/com.mattermost.plugin-splunk/api/v1"+SnoozeEndpoint, attachments[0].Actions[1].Integration.URL)
+}
+
+func Test_splunk_alertMessage(t *testing.T) {
+	payload := AlertActionWHPayload{SearchName: "Errors", ResultsLink: "http://splunk/results"}
+	s := newSplunk(&fakePluginAPI{}, nil)
+
+	assert.Equal(t, "New alert action received http://splunk/results", s.alertMessage(store.Alert{ID: "alert"}, payload))
+	assert.Equal(t, "**cpu-alerts**: New alert action received http://splunk/results",
+		s.alertMessage(store.Alert{ID: "alert", Name: "cpu-alerts"}, payload))
+	assert.Equal(t, "Errors: [results](http://splunk/results)",
+		s.alertMessage(store.Alert{ID: "alert", Template: `{{.SearchName}}: {{link "results" .ResultsLink}}`}, payload))
+	assert.Equal(t, "New alert action received http://splunk/results",
+		s.alertMessage(store.Alert{ID: "alert", Template: `{{.Unknown}}`}, payload), "falls back to default on render error")
+}
+
+func Test_splunk_RenameAlert(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	m := mock.NewMockStore(ctrl)
+	m.EXPECT().GetChannelAlertIDs("channel").Return([]string{"a1", "a2"}, nil).AnyTimes()
+	m.EXPECT().GetAlert("a1").Return(store.Alert{ID: "a1", ChannelID: "channel", Name: "cpu-alerts"}, nil).AnyTimes()
+	m.EXPECT().GetAlert("a2").Return(store.Alert{ID: "a2", ChannelID: "channel"}, nil).AnyTimes()
+	m.EXPECT().UpdateAlert(store.Alert{ID: "a1", ChannelID: "channel", Name: "disk-alerts"}).Return(nil)
+	m.EXPECT().UpdateAlert(store.Alert{ID: "a2", ChannelID: "channel", Name: "memory"}).Return(nil)
+
+	s := newSplunk(&fakePluginAPI{}, m)
+	assert.NoError(t, s.RenameAlert("channel", "CPU-Alerts", "disk-alerts"))
+	assert.NoError(t, s.RenameAlert("channel", "a2", "Memory"))
+	assert.Error(t, s.RenameAlert("channel", "a2", "cpu-alerts"), "name is used by other subscription")
+	assert.Error(t, s.RenameAlert("channel", "a2", "cpu alerts"), "invalid name")
+	assert.Error(t, s.RenameAlert("channel", "unknown", "cpu"))
+}
+
+func TestAlertActionWHPayload_Severity(t *testing.T) {
+	tests := []struct {
+		name    string
+		payload string
+		want    string
+	}{
+		{name: "named severity", payload: `{"result": {"severity": "Critical", "count": "3"}}`, want: "critical"},
+		{name: "numeric severity", payload: `{"result": {"severity": "5"}}`, want: "severe"},
+		{name: "urgency", payload: `{"result": {"urgency": "high"}}`, want: "high"},
+		{name: "multivalue", payload: `{"result": {"severity": ["info", "warn"]}}`, want: "info,warn"},
+		{name: "no severity", payload: `{"result": {"count": "3"}}`, want: ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var payload AlertActionWHPayload
+			assert.NoError(t, json.Unmarshal([]byte(tt.payload), &payload))
+			assert.Equal(t, tt.want, payload.Severity())
+		})
+	}
+}
+
+// failingPostAPI fails to create posts in the given channels.
+type failingPostAPI struct {
+	fakeDirectoryAPI
+	failing map[string]bool
+}
+
+func (f *failingPostAPI) CreatePost(post *model.Post) (*model.Post, error) {
+	if f.failing[post.ChannelId] {
+		return nil, errors.New("channel is read only")
+	}
+	return f.fakeDirectoryAPI.CreatePost(post)
+}
+
+func Test_splunk_NotifyReportsFailedPosts(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	is := assert.New(t)
+
+	m := mock.NewMockStore(ctrl)
+	m.EXPECT().GetAlert("alert").Return(store.Alert{ID: "alert", ChannelID: "channel"}, nil)
+	m.EXPECT().LoadEphemeral(gomock.Any(), gomock.Any()).Return(false, nil).AnyTimes()
+	m.EXPECT().StoreEphemeral(errorReportKey("Failed to post alert", "alert", "channel"), true, errorReportTTL).Return(nil)
+
+	api := &failingPostAPI{
+		fakeDirectoryAPI: fakeDirectoryAPI{
+			fakePluginAPI: fakePluginAPI{config: &config.Config{ErrorsChannel: "ops/splunk-errors"}},
+			channels:      map[string]*model.Channel{"ops/splunk-errors": {Id: "errors"}},
+		},
+		failing: map[string]bool{"channel": true},
+	}
+	s := newSplunk(api, m)
+	is.Error(s.Notify("alert", AlertActionWHPayload{}))
+
+	is.Len(api.posts, 1)
+	is.Equal("errors", api.posts[0].ChannelId)
+	is.Contains(api.posts[0].Message, "**Channel ID:** channel")
+}
+
+func Test_splunk_NotifyRetriesOnlyFailedChannels(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	is := assert.New(t)
+
+	var payload AlertActionWHPayload
+	is.NoError(json.Unmarshal([]byte(`{"sid": "rt_1", "result": {"severity": "critical"}}`), &payload))
+
+	delivered := make(map[string]bool)
+	m := mock.NewMockStore(ctrl)
+	m.EXPECT().GetAlert("alert").Return(store.Alert{ID: "alert", ChannelID: "channel"}, nil).Times(2)
+	m.EXPECT().LoadEphemeral(gomock.Any(), gomock.Any()).DoAndReturn(func(key string, _ interface{}) (bool, error) {
+		return delivered[key], nil
+	}).AnyTimes()
+	m.EXPECT().StoreEphemeral(gomock.Any(), gomock.Any(), gomock.Any()).DoAndReturn(func(key string, _ interface{}, _ time.Duration) error {
+		delivered[key] = true
+		return nil
+	}).AnyTimes()
+
+	api := &failingPostAPI{
+		fakeDirectoryAPI: fakeDirectoryAPI{
+			fakePluginAPI: fakePluginAPI{config: &config.Config{SeverityRouting: "critical = ops/incidents\ncritical = ops/oncall"}},
+			channels: map[string]*model.Channel{
+				"ops/incidents": {Id: "incidents"},
+				"ops/oncall":    {Id: "oncall"},
+			},
+		},
+		failing: map[string]bool{"oncall": true},
+	}
+	s := newSplunk(api, m)
+	is.Error(s.Notify("alert", payload))
+	is.Len(api.posts, 1)
+
+	delete(api.failing, "oncall")
+	is.NoError(s.Notify("alert", payload))
+	is.Len(api.posts, 2)
+	is.Equal("incidents", api.posts[0].ChannelId)
+	is.Equal("oncall", api.posts[1].ChannelId, "retry is posted only to the channel which failed")
+}
+
+func Test_splunk_NotifyPostsEveryFiring(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	is := assert.New(t)
+
+	delivered := make(map[string]bool)
+	m := mock.NewMockStore(ctrl)
+	m.EXPECT().GetAlert("alert").Return(store.Alert{ID: "alert", ChannelID: "channel"}, nil).AnyTimes()
+	m.EXPECT().LoadEphemeral(gomock.Any(), gomock.Any()).DoAndReturn(func(key string, _ interface{}) (bool, error) {
+		return delivered[key], nil
+	}).AnyTimes()
+	m.EXPECT().StoreEphemeral(gomock.Any(), true, alertDeliveryTTL).DoAndReturn(func(key string, _ interface{}, _ time.Duration) error {
+		delivered[key] = true
+		return nil
+	}).AnyTimes()
+
+	api := &fakePluginAPI{}
+	s := newSplunk(api, m)
+	first := AlertActionWHPayload{Sid: "scheduler__admin__search__RMD5_1", Raw: "static"}
+	second := first
+	second.Sid = "scheduler__admin__search__RMD5_2"
+	is.NoError(s.Notify("alert", first))
+	is.NoError(s.Notify("alert", second))
+	is.Len(api.posts, 2, "firings with the same content but other search jobs are posted")
+
+	noSid := AlertActionWHPayload{Raw: "static"}
+	is.NoError(s.Notify("alert", noSid))
+	is.NoError(s.Notify("alert", noSid))
+	is.Len(api.posts, 4, "payloads without a search job are not deduplicated")
+}
@@ -1,10 +1,16 @@
 package splunk
 
 import (
+	"context"
+	"encoding/json"
+	"fmt"
 	"io"
 	"net/http"
+	"strings"
 
 	"github.com/pkg/errors"
+
+	"github.com/mattermost/mattermost-plugin-splunk/server/store"
 )
 
 const (
@@ -15,6 +21,37 @@ const (
 type wHFirstResult struct {
 	SourceType string `json:"sourcetype"`
 	Count      string `json:"count"`
+
+	// Fields stores all fields of the result row
+	Fields map[string]interface{} `json:"-"`
+}
+
+// UnmarshalJSON decodes known fields of the result row and keeps all of them in Fields.
+func (r *wHFirstResult) UnmarshalJSON(data []byte) error {
+	type knownFields wHFirstResult
+	if err := json.Unmarshal(data, (*knownFields)(r)); err != nil {
+		return err
+	}
+	return json.Unmarshal(data, &r.Fields)
+}
+
+// Field returns value of the result row field as a string.
+// Multivalue fields are joined with commas.
+func (r wHFirstResult) Field(name string) string {
+	switch v := r.Fields[name].(type) {
+	case nil:
+		return ""
+	case string:
+		return v
+	case []interface{}:
+		values := make([]string, 0, len(v))
+		for _, value := range v {
+			values = append(values, fmt.Sprint(value))
+		}
+		return strings.Join(values, ",")
+	default:
+		return fmt.Sprint(v)
+	}
 }
 
 // AlertActionWHPayload is unmarshal-ed json payload of alert webhook action
@@ -33,24 +70,81 @@ type AlertActionWHPayload struct {
 
 	// Search app
 	App string `json:"app"`
+
+	// Name of the saved search that triggered the alert
+	SearchName string `json:"search_name"`
+
+	// Raw is the request body of subscriptions with the raw payload format
+	Raw string `json:"-"`
+}
+
+// splunkSeverities maps numeric splunk alert severities to their names.
+var splunkSeverities = map[string]string{
+	"1": "debug",
+	"2": "info",
+	"3": "warn",
+	"4": "error",
+	"5": "severe",
+	"6": "fatal",
+}
+
+// Severity returns lowercase severity of the alert taken from the severity or urgency
+// field of the result row, numeric splunk severities are converted to their names.
+// Returns empty string if result has no severity.
+func (p AlertActionWHPayload) Severity() string {
+	for _, field := range []string{"severity", "alert_severity", "urgency"} {
+		severity := strings.ToLower(strings.TrimSpace(p.Result.Field(field)))
+		if severity == "" {
+			continue
+		}
+		if name, ok := splunkSeverities[severity]; ok {
+			return name
+		}
+		return severity
+	}
+	return ""
 }
 
 // AlertActionFunc api users can add this function and after every webhook message
 // all of them will be notified
 type AlertActionFunc func(payload AlertActionWHPayload)
 
+// statusCodeError is returned for non-ok responses from splunk.
+type statusCodeError int
+
+func (e statusCodeError) Error() string {
+	return fmt.Sprintf("non-ok status code %v", int(e))
+}
+
+// isStatusCode checks if err was caused by response with the given status code.
+func isStatusCode(err error, code int) bool {
+	return errors.Cause(err) == statusCodeError(code)
+}
+
 func (s *splunk) doHTTPRequest(method string, url string, body io.Reader) (*http.Response, error) {
-	user := s.User()
+	return s.doHTTPRequestAs(s.User(), method, url, body)
+}
+
+// doHTTPRequestAs makes request to splunk on behalf of the given user.
+func (s *splunk) doHTTPRequestAs(user store.SplunkUser, method string, url string, body io.Reader) (*http.Response, error) {
+	return s.doHTTPRequestWithContext(context.Background(), user, method, url, body)
+}
+
+// doHTTPRequestWithContext makes request to splunk on behalf of the given user, ctx limits the whole request.
+func (s *splunk) doHTTPRequestWithContext(ctx context.Context, user store.SplunkUser, method string, url string, body io.Reader) (*http.Response, error) {
 	if user.Server == "" || user.Token == "" {
 		return nil, errors.New("unauthorized")
 	}
 
-	req, err := http.NewRequest(method, user.Server+url, body)
+	req, err := http.NewRequestWithContext(ctx, method, user.Server+url, body)
 	if err != nil {
 		return nil, errors.Wrap(err, "bad request")
 	}
 
-	req.Header.Set("Authorization", "Bearer "+user.Token)
+	req.Header.Set("Authorization", authorizationHeader(user))
+	if body != nil {
+		req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	}
 
 	resp, err := s.httpClient.Do(req)
 	if err != nil {
@@ -59,7 +153,7 @@ func (s *splunk) doHTTPRequest(method string, url string, body io.Reader) (*http
 
 	if resp.StatusCode != http.StatusOK {
 		_ = resp.Body.Close()
-		return nil, errors.Errorf("non-ok status code %v", resp.StatusCode)
+		return nil, statusCodeError(resp.StatusCode)
 	}
 	return resp, err
 }
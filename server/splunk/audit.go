@@ -0,0 +1,116 @@
+package splunk
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/mattermost/mattermost-server/v6/model"
+	"github.com/pkg/errors"
+
+	"github.com/mattermost/mattermost-plugin-splunk/server/store"
+)
+
+const (
+	// HECEventEndpoint endpoint of the HTTP Event Collector for JSON events
+	HECEventEndpoint = "/services/collector/event"
+
+	auditSource     = "mattermost-plugin-splunk"
+	auditSourceType = "mattermost:splunk:audit"
+	// auditForwardTimeout limits forwarding of a single audit entry
+	auditForwardTimeout = 10 * time.Second
+)
+
+// hecEvent is the HTTP Event Collector event of an audit entry
+type hecEvent struct {
+	Time       float64          `json:"time"`
+	Source     string           `json:"source"`
+	SourceType string           `json:"sourcetype"`
+	Index      string           `json:"index,omitempty"`
+	Event      store.AuditEntry `json:"event"`
+}
+
+// Audit records the entry in the audit log and forwards it to splunk if forwarding is configured.
+// Failures are only logged, they don't fail the audited action.
+func (s *splunk) Audit(entry store.AuditEntry) {
+	entry.ID = model.NewId()
+	entry.Time = model.GetMillis()
+
+	if err := s.Store.AppendAuditEntry(entry); err != nil {
+		s.LogError("failed to record audit entry", "action", entry.Action, "error", err.Error())
+	}
+
+	conf := s.GetConfiguration()
+	if conf.AuditHECURL == "" {
+		return
+	}
+	go func() {
+		if err := s.forwardAuditEntry(conf.AuditHECURL, conf.AuditHECToken, conf.AuditIndex, entry); err != nil {
+			s.LogWarn("failed to forward audit entry to splunk", "id", entry.ID, "error", err.Error())
+		}
+	}()
+}
+
+// AuditLog returns up to count latest audit log entries, newest first.
+func (s *splunk) AuditLog(count int) ([]store.AuditEntry, error) {
+	entries, err := s.Store.GetAuditEntries()
+	if err != nil {
+		return nil, err
+	}
+
+	var res []store.AuditEntry
+	for i := len(entries) - 1; i >= 0 && len(res) < count; i-- {
+		res = append(res, entries[i])
+	}
+	return res, nil
+}
+
+// AuditResult describes outcome of an audited action failing with err.
+func AuditResult(err error) string {
+	if err != nil {
+		return err.Error()
+	}
+	return "success"
+}
+
+// forwardAuditEntry sends the entry to the HTTP Event Collector at hecURL.
+// hecURL is either the collector base URL or its full event endpoint URL.
+func (s *splunk) forwardAuditEntry(hecURL string, token string, index string, entry store.AuditEntry) error {
+	hecURL = strings.TrimRight(hecURL, "/")
+	if !strings.Contains(hecURL, "/services/collector") {
+		hecURL += HECEventEndpoint
+	}
+
+	body, err := json.Marshal(hecEvent{
+		Time:       float64(entry.Time) / 1000,
+		Source:     auditSource,
+		SourceType: auditSourceType,
+		Index:      index,
+		Event:      entry,
+	})
+	if err != nil {
+		return errors.Wrap(err, "failed to encode audit event")
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), auditForwardTimeout)
+	defer cancel()
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, hecURL, bytes.NewReader(body))
+	if err != nil {
+		return errors.Wrap(err, "bad HTTP Event Collector URL")
+	}
+	req.Header.Set("Authorization", "Splunk "+token)
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := s.httpClient.Do(req)
+	if err != nil {
+		return errors.Wrap(err, "HTTP Event Collector is not reachable")
+	}
+	_ = resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return statusCodeError(resp.StatusCode)
+	}
+	return nil
+}
@@ -0,0 +1,55 @@
+package splunk
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/mattermost/mattermost-plugin-splunk/server/store"
+	"github.com/mattermost/mattermost-plugin-splunk/server/store/mock"
+)
+
+func Test_splunk_AuditLog(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	m := mock.NewMockStore(ctrl)
+	m.EXPECT().GetAuditEntries().Return([]store.AuditEntry{{ID: "1"}, {ID: "2"}, {ID: "3"}}, nil)
+
+	s := newSplunk(&fakePluginAPI{}, m)
+	entries, err := s.AuditLog(2)
+	assert.NoError(t, err)
+	assert.Equal(t, []store.AuditEntry{{ID: "3"}, {ID: "2"}}, entries, "newest entries come first")
+}
+
+func Test_splunk_forwardAuditEntry(t *testing.T) {
+	is := assert.New(t)
+
+	var event hecEvent
+	var authorization string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != HECEventEndpoint {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		authorization = r.Header.Get("Authorization")
+		is.NoError(json.NewDecoder(r.Body).Decode(&event))
+	}))
+	defer server.Close()
+
+	s := newSplunk(&fakePluginAPI{}, nil)
+	entry := store.AuditEntry{ID: "1", Time: 1500, ActorID: "admin", Action: "auth/login", Result: "success"}
+	is.NoError(s.forwardAuditEntry(server.URL+"/", "hec-token", "audit", entry))
+
+	is.Equal("Splunk hec-token", authorization)
+	is.Equal(entry, event.Event)
+	is.Equal(1.5, event.Time)
+	is.Equal("audit", event.Index)
+	is.Equal(auditSourceType, event.SourceType)
+
+	is.Error(s.forwardAuditEntry(server.URL+"/services/collector/missing", "hec-token", "", entry))
+}
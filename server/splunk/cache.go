@@ -0,0 +1,99 @@
+package splunk
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"time"
+
+	"github.com/mattermost/mattermost-server/v6/model"
+
+	"github.com/mattermost/mattermost-plugin-splunk/server/store"
+)
+
+const (
+	metadataCacheKeyPrefix = "splunkmeta_"
+	// metadataGenerationTTL is how long the cache generation of a user is kept,
+	// entries of an expired generation are not used anymore
+	metadataGenerationTTL = 30 * 24 * time.Hour
+
+	// kinds of cached metadata
+	cacheSavedSearch = "savedsearch"
+	cacheIndex       = "index"
+	cacheSearch      = "search"
+)
+
+// cachedLookup loads value of the metadata lookup of the splunk user into v
+// or calls fetch to fill v and caches it for the configured TTL.
+// Failed lookups are not cached. Cache failures are only logged, the lookup is made then.
+func (s *splunk) cachedLookup(user store.SplunkUser, kind string, name string, v interface{}, fetch func() error) error {
+	ttl := time.Duration(s.GetConfiguration().MetadataCacheTTL) * time.Minute
+	if ttl <= 0 {
+		return fetch()
+	}
+
+	generation, err := s.cacheGeneration(user)
+	if err != nil {
+		s.LogWarn("failed to load metadata cache", "error", err.Error())
+		return fetch()
+	}
+
+	key := metadataCacheKey(generation, kind, name)
+	if found, loadErr := s.Store.LoadEphemeral(key, v); loadErr != nil {
+		s.LogWarn("failed to load cached metadata", "kind", kind, "error", loadErr.Error())
+	} else if found {
+		return nil
+	}
+
+	if err = fetch(); err != nil {
+		return err
+	}
+	if err = s.Store.StoreEphemeral(key, v, ttl); err != nil {
+		s.LogWarn("failed to cache metadata", "kind", kind, "error", err.Error())
+	}
+	return nil
+}
+
+// invalidateMetadata drops all cached metadata of the splunk user.
+func (s *splunk) invalidateMetadata(user store.SplunkUser) {
+	if err := s.Store.DeleteEphemeral(cacheGenerationKey(user)); err != nil {
+		s.LogWarn("failed to invalidate metadata cache", "error", err.Error())
+	}
+}
+
+// cacheGeneration returns the current cache generation of the user, which is part of all its cache keys
+// so that all entries are invalidated at once by removing it.
+func (s *splunk) cacheGeneration(user store.SplunkUser) (string, error) {
+	key := cacheGenerationKey(user)
+	var generation string
+	found, err := s.Store.LoadEphemeral(key, &generation)
+	if err != nil {
+		return "", err
+	}
+	if found {
+		return generation, nil
+	}
+
+	generation = model.NewId()
+	if err = s.Store.StoreEphemeral(key, generation, metadataGenerationTTL); err != nil {
+		return "", err
+	}
+	return generation, nil
+}
+
+func cacheGenerationKey(user store.SplunkUser) string {
+	return metadataCacheKeyPrefix + "gen_" + hash(user.Server, user.UserName)
+}
+
+func metadataCacheKey(generation string, kind string, name string) string {
+	return metadataCacheKeyPrefix + hash(generation, kind, name)
+}
+
+// hash returns hex encoded sha256 of the parts, which keeps keys short and free of user input.
+func hash(parts ...string) string {
+	h := sha256.New()
+	for _, part := range parts {
+		_, _ = h.Write([]byte(part))
+		_, _ = h.Write([]byte{0})
+	}
+	return hex.EncodeToString(h.Sum(nil))
+}
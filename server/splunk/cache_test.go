@@ -0,0 +1,74 @@
+package splunk
+
+import (
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/mattermost/mattermost-plugin-splunk/server/config"
+	"github.com/mattermost/mattermost-plugin-splunk/server/store"
+	"github.com/mattermost/mattermost-plugin-splunk/server/store/mock"
+)
+
+func Test_splunk_cachedLookup(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	is := assert.New(t)
+
+	user := store.SplunkUser{Server: "https://splunk:8089", UserName: "admin"}
+	key := metadataCacheKey("g1", cacheIndex, "main")
+
+	m := mock.NewMockStore(ctrl)
+	m.EXPECT().LoadEphemeral(cacheGenerationKey(user), gomock.Any()).DoAndReturn(func(_ string, v interface{}) (bool, error) {
+		*v.(*string) = "g1"
+		return true, nil
+	}).AnyTimes()
+	gomock.InOrder(
+		m.EXPECT().LoadEphemeral(key, gomock.Any()).Return(false, nil),
+		m.EXPECT().StoreEphemeral(key, gomock.Any(), 5*time.Minute).Return(nil),
+		m.EXPECT().LoadEphemeral(key, gomock.Any()).Return(true, nil),
+	)
+	m.EXPECT().LoadEphemeral(metadataCacheKey("g1", cacheIndex, "missing"), gomock.Any()).Return(false, nil)
+
+	s := newSplunk(&fakePluginAPI{config: &config.Config{MetadataCacheTTL: 5}}, m)
+
+	calls := 0
+	fetch := func() error {
+		calls++
+		return nil
+	}
+	var valid bool
+	is.NoError(s.cachedLookup(user, cacheIndex, "main", &valid, fetch))
+	is.NoError(s.cachedLookup(user, cacheIndex, "main", &valid, fetch))
+	is.Equal(1, calls, "second lookup is cached")
+
+	is.Error(s.cachedLookup(user, cacheIndex, "missing", &valid, func() error { return errors.New("not found") }), "failed lookups are not cached")
+}
+
+func Test_splunk_cachedLookupDisabled(t *testing.T) {
+	s := newSplunk(&fakePluginAPI{}, nil)
+
+	calls := 0
+	var valid bool
+	for i := 0; i < 2; i++ {
+		assert.NoError(t, s.cachedLookup(store.SplunkUser{}, cacheIndex, "main", &valid, func() error {
+			calls++
+			return nil
+		}))
+	}
+	assert.Equal(t, 2, calls)
+}
+
+func Test_splunk_invalidateMetadata(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	user := store.SplunkUser{Server: "https://splunk:8089", UserName: "admin"}
+	m := mock.NewMockStore(ctrl)
+	m.EXPECT().DeleteEphemeral(cacheGenerationKey(user)).Return(nil)
+
+	newSplunk(&fakePluginAPI{}, m).invalidateMetadata(user)
+}
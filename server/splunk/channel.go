@@ -0,0 +1,33 @@
+package splunk
+
+import (
+	"github.com/pkg/errors"
+)
+
+// BindChannel makes server default splunk server for all commands executed in the channel.
+func (s *splunk) BindChannel(channelID string, server string) error {
+	err := s.Store.SetChannelServer(channelID, server)
+	if err != nil {
+		return errors.Wrap(err, "error in binding server to channel")
+	}
+	return nil
+}
+
+// UnbindChannel removes default splunk server of the channel.
+func (s *splunk) UnbindChannel(channelID string) error {
+	err := s.Store.DeleteChannelServer(channelID)
+	if err != nil {
+		return errors.Wrap(err, "error in unbinding server from channel")
+	}
+	return nil
+}
+
+// ChannelBinding returns splunk server bound to the channel
+// or empty string if there is none.
+func (s *splunk) ChannelBinding(channelID string) (string, error) {
+	server, err := s.Store.GetChannelServer(channelID)
+	if err != nil {
+		return "", errors.Wrap(err, "error in getting channel server")
+	}
+	return server, nil
+}
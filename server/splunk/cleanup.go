@@ -0,0 +1,128 @@
+package splunk
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/mattermost/mattermost-server/v6/model"
+	"github.com/pkg/errors"
+
+	"github.com/mattermost/mattermost-plugin-splunk/server/store"
+)
+
+// CleanupSubscriptions finds alert subscriptions whose channel or creator no longer exists
+// or whose saved search was deleted. Orphaned subscriptions are removed
+// if RemoveOrphanedSubscriptions is set and only reported in logs otherwise.
+func (s *splunk) CleanupSubscriptions() {
+	alerts, err := s.Store.GetAlerts()
+	if err != nil {
+		s.LogError("failed to load alert subscriptions", "error", err.Error())
+		return
+	}
+
+	remove := s.GetConfiguration().RemoveOrphanedSubscriptions
+	for _, alert := range alerts {
+		reason, channelExists, removable := s.orphanReason(alert)
+		if reason == "" {
+			continue
+		}
+
+		if !remove || !removable {
+			s.LogWarn("orphaned alert subscription found", "alertID", alert.ID, "channelID", alert.ChannelID, "reason", reason)
+			continue
+		}
+
+		if err = s.Store.DeleteChannelAlert(alert.ChannelID, alert.ID); err != nil {
+			s.LogError("failed to remove orphaned alert subscription", "alertID", alert.ID, "error", err.Error())
+			continue
+		}
+		s.LogInfo("orphaned alert subscription removed", "alertID", alert.ID, "channelID", alert.ChannelID, "reason", reason)
+
+		if channelExists {
+			_, err = s.CreatePost(&model.Post{
+				UserId:    s.BotUser(),
+				ChannelId: alert.ChannelID,
+				Message:   fmt.Sprintf("Alert subscription %s was removed because %s.", alert.ID, reason),
+			})
+			if err != nil {
+				s.LogWarn("failed to notify channel about removed alert subscription", "alertID", alert.ID, "error", err.Error())
+			}
+		}
+	}
+}
+
+// orphanReason returns why the subscription is orphaned or an empty string if it's not,
+// whether subscription channel still exists and whether the subscription can be removed.
+// Subscriptions are only considered orphaned if missing entities are confirmed,
+// not when they can't be checked. Subscriptions of archived channels and deactivated creators
+// are reported but not removable, as the channel or the user can be restored.
+func (s *splunk) orphanReason(alert store.Alert) (string, bool, bool) {
+	channel, err := s.GetChannel(alert.ChannelID)
+	if err != nil {
+		if !isNotFound(err) {
+			s.LogWarn("failed to check alert subscription channel", "alertID", alert.ID, "error", err.Error())
+			return "", false, false
+		}
+		return "its channel no longer exists", false, true
+	}
+	if channel.DeleteAt != 0 {
+		return "its channel is archived", false, false
+	}
+
+	if alert.CreatorID != "" {
+		creator, userErr := s.GetUser(alert.CreatorID)
+		if userErr != nil {
+			if !isNotFound(userErr) {
+				s.LogWarn("failed to check alert subscription creator", "alertID", alert.ID, "error", userErr.Error())
+				return "", true, false
+			}
+			return "its creator no longer exists", true, true
+		}
+		if creator.DeleteAt != 0 {
+			return "its creator is deactivated", true, false
+		}
+	}
+
+	if alert.SearchName == "" {
+		return "", true, false
+	}
+	user, err := s.Store.User(alert.CreatorID, alert.Server, alert.UserName)
+	if err != nil {
+		return "", true, false
+	}
+	if err = s.checkSavedSearchIn(user, alert.Owner, alert.App, alert.SearchName); isStatusCode(err, http.StatusNotFound) {
+		return fmt.Sprintf("saved search %q was deleted", alert.SearchName), true, true
+	}
+	return "", true, false
+}
+
+// isNotFound returns true if the error of the plugin API confirms that the entity doesn't exist.
+func isNotFound(err error) bool {
+	appErr, ok := errors.Cause(err).(*model.AppError)
+	return ok && appErr.StatusCode == http.StatusNotFound
+}
+
+// checkSavedSearchIn checks if saved search exists in app namespace of the owner
+// empty owner and app match any namespace. Existing saved searches are cached.
+func (s *splunk) checkSavedSearchIn(user store.SplunkUser, owner string, app string, name string) error {
+	if owner == "" {
+		owner = "-"
+	}
+	if app == "" {
+		app = "-"
+	}
+	endpoint := strings.Join([]string{"/servicesNS", url.PathEscape(owner), url.PathEscape(app), "saved/searches", url.PathEscape(name)}, "/")
+
+	var exists bool
+	return s.cachedLookup(user, cacheSavedSearch, endpoint, &exists, func() error {
+		resp, err := s.doHTTPRequestAs(user, http.MethodGet, endpoint, nil)
+		if err != nil {
+			return errors.Wrapf(err, "saved search %q is not available", name)
+		}
+		_ = resp.Body.Close()
+		exists = true
+		return nil
+	})
+}
@@ -0,0 +1,51 @@
+package splunk
+
+import (
+	"testing"
+
+	"github.com/mattermost/mattermost-server/v6/model"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/mattermost/mattermost-plugin-splunk/server/store"
+)
+
+func Test_splunk_orphanReason(t *testing.T) {
+	api := &fakeDirectoryAPI{
+		channels: map[string]*model.Channel{
+			"team/town-square": {Id: "channel"},
+			"team/archived":    {Id: "archived", DeleteAt: 1},
+		},
+		users: map[string]*model.User{
+			"john":    {Id: "john"},
+			"retired": {Id: "retired", DeleteAt: 1},
+		},
+		failing: map[string]bool{"unreachable": true, "unknown": true},
+	}
+	s := newSplunk(api, nil)
+
+	tests := []struct {
+		name          string
+		alert         store.Alert
+		orphaned      bool
+		channelExists bool
+		removable     bool
+	}{
+		{name: "valid", alert: store.Alert{ChannelID: "channel", CreatorID: "john"}, channelExists: true},
+		{name: "legacy without creator", alert: store.Alert{ChannelID: "channel"}, channelExists: true},
+		{name: "missing channel", alert: store.Alert{ChannelID: "missing", CreatorID: "john"}, orphaned: true, removable: true},
+		{name: "archived channel", alert: store.Alert{ChannelID: "archived", CreatorID: "john"}, orphaned: true},
+		{name: "unreachable channel", alert: store.Alert{ChannelID: "unreachable", CreatorID: "john"}},
+		{name: "deactivated creator", alert: store.Alert{ChannelID: "channel", CreatorID: "retired"}, orphaned: true, channelExists: true},
+		{name: "missing creator", alert: store.Alert{ChannelID: "channel", CreatorID: "missing"}, orphaned: true, channelExists: true, removable: true},
+		{name: "unknown creator", alert: store.Alert{ChannelID: "channel", CreatorID: "unknown"}, channelExists: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			reason, channelExists, removable := s.orphanReason(tt.alert)
+			assert.Equal(t, tt.orphaned, reason != "")
+			assert.Equal(t, tt.channelExists, channelExists)
+			assert.Equal(t, tt.removable, removable)
+		})
+	}
+}
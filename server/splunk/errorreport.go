@@ -0,0 +1,118 @@
+package splunk
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/mattermost/mattermost-server/v6/model"
+	"github.com/pkg/errors"
+)
+
+// errorReportTTL is how long repeated errors with the same reason, alert and channel are not reported again.
+// They are still logged with their correlation IDs.
+const errorReportTTL = 5 * time.Minute
+
+// ErrAlertNotFound is returned when alert is delivered for an unknown subscription.
+var ErrAlertNotFound = errors.New("alert subscription not found")
+
+// DeliveryError describes rejected alert or failed post reported to the errors channel.
+type DeliveryError struct {
+	// CorrelationID identifies the failure in the report, server logs and webhook response.
+	CorrelationID string
+	// Reason is a short description of what failed.
+	Reason  string
+	AlertID string
+	// Source is the address alert was received from.
+	Source string
+	// ChannelID is the channel the alert failed to be posted to.
+	ChannelID string
+	// Unauthenticated is set for requests rejected before their secret is checked,
+	// their alert ID is not trusted, so it's left out of the report and repeated errors are only reported by reason.
+	Unauthenticated bool
+	Err             error
+}
+
+// ReportDeliveryError logs the error and posts it to the errors channel if one is configured.
+func (s *splunk) ReportDeliveryError(e DeliveryError) {
+	details := ""
+	if e.Err != nil {
+		details = e.Err.Error()
+	}
+	s.LogError(e.Reason, "correlation_id", e.CorrelationID, "alert_id", e.AlertID, "channel_id", e.ChannelID, "source", e.Source, "error", details)
+
+	if s.GetConfiguration().ErrorsChannel == "" {
+		return
+	}
+
+	if e.Unauthenticated {
+		e.AlertID = ""
+	}
+	key := errorReportKey(e.Reason, e.AlertID, e.ChannelID)
+	var reported bool
+	if found, err := s.Store.LoadEphemeral(key, &reported); err != nil {
+		s.LogWarn("failed to check reported errors", "error", err.Error())
+	} else if found {
+		return
+	}
+
+	channelID, err := s.errorsChannelID()
+	if err != nil {
+		s.LogWarn("failed to find errors channel", "error", err.Error())
+		return
+	}
+
+	message := fmt.Sprintf("#### Splunk alert delivery failed\n**Reason:** %s\n", e.Reason)
+	if e.AlertID != "" {
+		message += fmt.Sprintf("**Alert ID:** %s\n", e.AlertID)
+	}
+	if e.ChannelID != "" {
+		message += fmt.Sprintf("**Channel ID:** %s\n", e.ChannelID)
+	}
+	if e.Source != "" {
+		message += fmt.Sprintf("**Source:** %s\n", e.Source)
+	}
+	if details != "" {
+		message += fmt.Sprintf("**Details:** %s\n", details)
+	}
+	message += fmt.Sprintf("**Correlation ID:** %s\n\nSame errors are not reported again for %v, search the server logs by the correlation ID.", e.CorrelationID, errorReportTTL)
+
+	// failures to report are only logged, reporting them would fail again
+	if _, err = s.CreatePost(&model.Post{
+		UserId:    s.BotUser(),
+		ChannelId: channelID,
+		Message:   message,
+	}); err != nil {
+		s.LogWarn("failed to post to errors channel", "correlation_id", e.CorrelationID, "error", err.Error())
+		return
+	}
+
+	if err = s.Store.StoreEphemeral(key, true, errorReportTTL); err != nil {
+		s.LogWarn("failed to remember reported error", "error", err.Error())
+	}
+}
+
+// errorsChannelID returns ID of the configured errors channel, empty if there is none.
+func (s *splunk) errorsChannelID() (string, error) {
+	errorsChannel := s.GetConfiguration().ErrorsChannel
+	if errorsChannel == "" {
+		return "", nil
+	}
+
+	idx := strings.Index(errorsChannel, "/")
+	if idx == -1 {
+		return "", errors.Errorf("invalid errors channel %s, expected team/channel", errorsChannel)
+	}
+	channel, err := s.GetChannelByNameForTeamName(errorsChannel[:idx], errorsChannel[idx+1:])
+	if err != nil {
+		return "", errors.Wrapf(err, "errors channel %s not found", errorsChannel)
+	}
+	return channel.Id, nil
+}
+
+func errorReportKey(reason string, alertID string, channelID string) string {
+	sum := sha256.Sum256([]byte(reason + "\x00" + alertID + "\x00" + channelID))
+	return "errreport_" + hex.EncodeToString(sum[:])
+}
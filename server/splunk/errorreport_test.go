@@ -0,0 +1,58 @@
+package splunk
+
+import (
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/mattermost/mattermost-server/v6/model"
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/mattermost/mattermost-plugin-splunk/server/config"
+	"github.com/mattermost/mattermost-plugin-splunk/server/store/mock"
+)
+
+func Test_splunk_ReportDeliveryError(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	is := assert.New(t)
+
+	key := errorReportKey("Bad webhook request. Invalid 'secret'", "", "")
+	m := mock.NewMockStore(ctrl)
+	gomock.InOrder(
+		m.EXPECT().LoadEphemeral(key, gomock.Any()).Return(false, nil),
+		m.EXPECT().StoreEphemeral(key, true, errorReportTTL).Return(nil),
+		m.EXPECT().LoadEphemeral(key, gomock.Any()).Return(true, nil),
+	)
+
+	api := &fakeDirectoryAPI{
+		fakePluginAPI: fakePluginAPI{config: &config.Config{ErrorsChannel: "ops/splunk-errors"}},
+		channels:      map[string]*model.Channel{"ops/splunk-errors": {Id: "errors"}},
+	}
+	s := newSplunk(api, m)
+	e := DeliveryError{
+		CorrelationID:   "correlation",
+		Reason:          "Bad webhook request. Invalid 'secret'",
+		AlertID:         "alert",
+		Source:          "10.0.0.1",
+		Unauthenticated: true,
+		Err:             errors.New("details"),
+	}
+	s.ReportDeliveryError(e)
+	e.AlertID = "other"
+	s.ReportDeliveryError(e)
+
+	is.Len(api.posts, 1, "repeated error is not reported again for any alert ID")
+	is.Equal("errors", api.posts[0].ChannelId)
+	is.Contains(api.posts[0].Message, "**Correlation ID:** correlation")
+	is.Contains(api.posts[0].Message, "**Source:** 10.0.0.1")
+	is.Contains(api.posts[0].Message, "**Details:** details")
+	is.NotContains(api.posts[0].Message, "**Alert ID:**")
+}
+
+func Test_splunk_ReportDeliveryErrorWithoutChannel(t *testing.T) {
+	api := &fakePluginAPI{}
+	s := newSplunk(api, nil)
+	s.ReportDeliveryError(DeliveryError{CorrelationID: "correlation", Reason: "Bad Request"})
+	assert.Empty(t, api.posts)
+}
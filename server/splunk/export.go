@@ -0,0 +1,180 @@
+package splunk
+
+import (
+	"strings"
+
+	"github.com/pkg/errors"
+
+	"github.com/mattermost/mattermost-plugin-splunk/server/store"
+)
+
+// ExportData returns all plugin data as a bundle
+// user tokens are only included if includeTokens is set.
+func (s *splunk) ExportData(includeTokens bool) (store.Bundle, error) {
+	bundle, err := s.Store.Export(includeTokens)
+	if err != nil {
+		return store.Bundle{}, errors.Wrap(err, "error in exporting data")
+	}
+
+	bundle.ChannelNames = make(map[string]string)
+	bundle.UserNames = make(map[string]string)
+	addChannel := func(channelID string) {
+		if _, ok := bundle.ChannelNames[channelID]; ok {
+			return
+		}
+		if name, nameErr := s.channelName(channelID); nameErr == nil {
+			bundle.ChannelNames[channelID] = name
+		}
+	}
+	addUser := func(userID string) {
+		if _, ok := bundle.UserNames[userID]; ok || userID == "" {
+			return
+		}
+		if user, userErr := s.GetUser(userID); userErr == nil {
+			bundle.UserNames[userID] = user.Username
+		}
+	}
+
+	for _, u := range bundle.Users {
+		addUser(u.MattermostUserID)
+	}
+	for _, alert := range bundle.Alerts {
+		addChannel(alert.ChannelID)
+		addUser(alert.CreatorID)
+	}
+	for channelID := range bundle.ChannelServers {
+		addChannel(channelID)
+	}
+	for _, schedule := range bundle.Reports {
+		addChannel(schedule.ChannelID)
+		addUser(schedule.CreatorID)
+	}
+	for _, source := range bundle.LogSources {
+		addChannel(source.ChannelID)
+		addUser(source.CreatorID)
+	}
+	return bundle, nil
+}
+
+// ImportData stores data from the bundle exported on this or another workspace.
+// Channels and users which don't exist on this workspace are looked up by names,
+// entries referencing channels or users which can't be found are skipped and listed in the summary.
+func (s *splunk) ImportData(bundle store.Bundle) (store.ImportSummary, error) {
+	var skipped []string
+	channelIDs := make(map[string]string)
+	channelID := func(id string) string {
+		if newID, ok := channelIDs[id]; ok {
+			return newID
+		}
+		channelIDs[id] = s.resolveChannel(id, bundle.ChannelNames[id])
+		return channelIDs[id]
+	}
+	userIDs := make(map[string]string)
+	userID := func(id string) string {
+		if newID, ok := userIDs[id]; ok || id == "" {
+			return newID
+		}
+		userIDs[id] = s.resolveUser(id, bundle.UserNames[id])
+		return userIDs[id]
+	}
+
+	resolved := store.Bundle{
+		Version:        bundle.Version,
+		ChannelServers: make(map[string]string),
+	}
+	for _, u := range bundle.Users {
+		if u.MattermostUserID = userID(u.MattermostUserID); u.MattermostUserID == "" {
+			continue
+		}
+		resolved.Users = append(resolved.Users, u)
+	}
+	for _, alert := range bundle.Alerts {
+		if alert.ChannelID = channelID(alert.ChannelID); alert.ChannelID == "" {
+			skipped = append(skipped, "alert "+alert.ID+": channel not found")
+			continue
+		}
+		alert.CreatorID = userID(alert.CreatorID)
+		resolved.Alerts = append(resolved.Alerts, alert)
+	}
+	for id, server := range bundle.ChannelServers {
+		if id = channelID(id); id != "" {
+			resolved.ChannelServers[id] = server
+		}
+	}
+	for _, schedule := range bundle.Reports {
+		schedule.ChannelID = channelID(schedule.ChannelID)
+		schedule.CreatorID = userID(schedule.CreatorID)
+		if schedule.ChannelID == "" || schedule.CreatorID == "" {
+			skipped = append(skipped, "report "+schedule.ID+": channel or creator not found")
+			continue
+		}
+		resolved.Reports = append(resolved.Reports, schedule)
+	}
+	for _, source := range bundle.LogSources {
+		if source.ChannelID = channelID(source.ChannelID); source.ChannelID == "" {
+			skipped = append(skipped, "log source "+source.Alias+": channel not found")
+			continue
+		}
+		source.CreatorID = userID(source.CreatorID)
+		resolved.LogSources = append(resolved.LogSources, source)
+	}
+
+	summary, err := s.Store.Import(resolved)
+	summary.Skipped = append(skipped, summary.Skipped...)
+	for _, mismatch := range summary.Mismatches {
+		s.LogWarn("imported alert subscription didn't match the stored index", "mismatch", mismatch)
+	}
+	if err != nil {
+		return summary, errors.Wrap(err, "error in importing data")
+	}
+	return summary, nil
+}
+
+// channelName returns channel name in team-name/channel-name form.
+func (s *splunk) channelName(channelID string) (string, error) {
+	channel, err := s.GetChannel(channelID)
+	if err != nil {
+		return "", err
+	}
+	if channel.TeamId == "" {
+		return "", errors.New("channel doesn't belong to a team")
+	}
+	team, err := s.GetTeam(channel.TeamId)
+	if err != nil {
+		return "", err
+	}
+	return team.Name + "/" + channel.Name, nil
+}
+
+// resolveChannel returns ID of the channel on this workspace
+// or an empty string if it can't be found.
+func (s *splunk) resolveChannel(channelID string, name string) string {
+	if _, err := s.GetChannel(channelID); err == nil {
+		return channelID
+	}
+	parts := strings.SplitN(name, "/", 2)
+	if len(parts) != 2 {
+		return ""
+	}
+	channel, err := s.GetChannelByNameForTeamName(parts[0], parts[1])
+	if err != nil {
+		return ""
+	}
+	return channel.Id
+}
+
+// resolveUser returns ID of the user on this workspace
+// or an empty string if it can't be found.
+func (s *splunk) resolveUser(userID string, username string) string {
+	if _, err := s.GetUser(userID); err == nil {
+		return userID
+	}
+	if username == "" {
+		return ""
+	}
+	user, err := s.GetUserByUsername(username)
+	if err != nil {
+		return ""
+	}
+	return user.Id
+}
@@ -0,0 +1,110 @@
+package splunk
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/mattermost/mattermost-server/v6/model"
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/mattermost/mattermost-plugin-splunk/server/store"
+	"github.com/mattermost/mattermost-plugin-splunk/server/store/mock"
+)
+
+type fakeDirectoryAPI struct {
+	fakePluginAPI
+	channels map[string]*model.Channel
+	users    map[string]*model.User
+	// failing are IDs of channels and users which can't be loaded, other missing ones are not found
+	failing map[string]bool
+}
+
+// errNotFound is the error plugin API returns for missing channels and users.
+var errNotFound = model.NewAppError("fake", "not_found", nil, "", http.StatusNotFound)
+
+func (f *fakeDirectoryAPI) GetChannel(channelID string) (*model.Channel, error) {
+	if f.failing[channelID] {
+		return nil, errors.New("connection refused")
+	}
+	for _, channel := range f.channels {
+		if channel.Id == channelID {
+			return channel, nil
+		}
+	}
+	return nil, errNotFound
+}
+
+func (f *fakeDirectoryAPI) GetChannelByNameForTeamName(teamName, channelName string) (*model.Channel, error) {
+	if channel, ok := f.channels[teamName+"/"+channelName]; ok {
+		return channel, nil
+	}
+	return nil, errors.New("not found")
+}
+
+func (f *fakeDirectoryAPI) GetUser(userID string) (*model.User, error) {
+	if f.failing[userID] {
+		return nil, errors.New("connection refused")
+	}
+	for _, user := range f.users {
+		if user.Id == userID {
+			return user, nil
+		}
+	}
+	return nil, errNotFound
+}
+
+func (f *fakeDirectoryAPI) GetUserByUsername(username string) (*model.User, error) {
+	if user, ok := f.users[username]; ok {
+		return user, nil
+	}
+	return nil, errors.New("not found")
+}
+
+func Test_splunk_ImportDataResolvesIDs(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	api := &fakeDirectoryAPI{
+		channels: map[string]*model.Channel{
+			"team/same":    {Id: "same-channel"},
+			"team/renamed": {Id: "new-channel"},
+		},
+		users: map[string]*model.User{
+			"john": {Id: "new-john"},
+		},
+	}
+
+	m := mock.NewMockStore(ctrl)
+	m.EXPECT().Import(store.Bundle{
+		Version: store.BundleVersion,
+		Users: []store.UserBundle{
+			{MattermostUserID: "new-john", SplunkUsers: []store.SplunkUser{{Server: "s", UserName: "u", Token: "t"}}},
+		},
+		Alerts: []store.Alert{
+			{ID: "a1", ChannelID: "same-channel", CreatorID: "new-john"},
+			{ID: "a2", ChannelID: "new-channel"},
+		},
+		ChannelServers: map[string]string{"new-channel": "https://splunk"},
+	}).Return(store.ImportSummary{Skipped: []string{"report r1: failed"}}, nil)
+
+	s := newSplunk(api, m)
+	summary, err := s.ImportData(store.Bundle{
+		Version: store.BundleVersion,
+		Users: []store.UserBundle{
+			{MattermostUserID: "old-john", SplunkUsers: []store.SplunkUser{{Server: "s", UserName: "u", Token: "t"}}},
+			{MattermostUserID: "old-jane"},
+		},
+		Alerts: []store.Alert{
+			{ID: "a1", ChannelID: "same-channel", CreatorID: "old-john"},
+			{ID: "a2", ChannelID: "old-channel", CreatorID: "old-jane"},
+			{ID: "a3", ChannelID: "missing-channel"},
+		},
+		ChannelServers: map[string]string{"old-channel": "https://splunk"},
+		ChannelNames:   map[string]string{"old-channel": "team/renamed", "missing-channel": "team/missing"},
+		UserNames:      map[string]string{"old-john": "john", "old-jane": "jane"},
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"alert a3: channel not found", "report r1: failed"}, summary.Skipped)
+}
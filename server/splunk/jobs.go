@@ -0,0 +1,17 @@
+package splunk
+
+import (
+	"time"
+
+	"github.com/mattermost/mattermost-plugin-splunk/server/jobs"
+)
+
+// Jobs returns recurring jobs of the splunk subsystems, they are run once per interval across the cluster.
+func (s *splunk) Jobs() []jobs.Job {
+	return []jobs.Job{
+		{ID: "report_scheduler", Interval: time.Minute, Rounded: true, Run: s.RunDueReports},
+		{ID: "subscription_cleanup", Interval: 24 * time.Hour, Run: s.CleanupSubscriptions},
+		{ID: "credential_revocation", Interval: time.Hour, Run: s.RevokeDeactivatedUsers},
+		{ID: "mute_resume", Interval: time.Minute, Rounded: true, Run: s.ResumeMutedAlerts},
+	}
+}
@@ -1,13 +1,33 @@
 package splunk
 
 import (
-	"encoding/xml"
 	"fmt"
 	"net/http"
+	"net/url"
+	"regexp"
 	"strings"
-	"time"
 
 	"github.com/pkg/errors"
+
+	"github.com/mattermost/mattermost-plugin-splunk/server/store"
+)
+
+const (
+	// IndexesEndpoint endpoint for index info retrieval
+	IndexesEndpoint = "/services/data/indexes"
+	// SearchParserEndpoint endpoint for search syntax validation
+	SearchParserEndpoint = "/services/search/parser"
+
+	// logRows is the number of events shown for a log source
+	logRows = 50
+	// logEarliest limits log source searches to recent events
+	logEarliest = "-24h"
+)
+
+var (
+	// namePattern matches names of log sources and alert subscriptions
+	namePattern      = regexp.MustCompile(`^[a-z0-9][a-z0-9_-]{0,63}$`)
+	indexNamePattern = regexp.MustCompile(`^[a-z0-9_][a-z0-9_-]*$`)
 )
 
 // LogResults stores result of log fetching
@@ -22,92 +42,142 @@ type LogResults struct {
 	} `xml:"result"`
 }
 
-func (s *splunk) Logs(source string) (LogResults, error) {
-	bodyString := fmt.Sprintf("search index=_internal source=%s", source)
-	resp, err := s.doHTTPRequest(http.MethodPost, LogsEndpoint, strings.NewReader(bodyString))
-	if err != nil {
-		return LogResults{}, errors.Wrap(err, "no log info")
-	}
-	defer func() { _ = resp.Body.Close() }()
-	var logInfo logInfo
-	if err = xml.NewDecoder(resp.Body).Decode(&logInfo); err != nil {
-		return LogResults{}, errors.Wrap(err, "unexpected response")
+// AddLogSource validates the log source against splunk server and stores it in the channel.
+// definition is either index, index/sourcetype or an SPL search.
+func (s *splunk) AddLogSource(channelID string, creatorID string, alias string, definition string) (store.LogSource, error) {
+	alias = strings.ToLower(alias)
+	if !namePattern.MatchString(alias) {
+		return store.LogSource{}, errors.New("alias can only have letters, digits, - and _")
 	}
 
-	id, err := logInfo.getLogID()
-	if err != nil {
-		return LogResults{}, errors.Wrap(err, "can't get log id")
+	source := parseLogSource(definition)
+	if source.Index == "" && source.SPL == "" {
+		return store.LogSource{}, errors.New("please provide index, index/sourcetype or SPL search of the log source")
 	}
+	source.Alias, source.ChannelID, source.CreatorID = alias, channelID, creatorID
 
-	resp, err = s.doHTTPRequest(http.MethodGet, LogsEndpoint+"/"+id+"/results", nil)
+	existing, err := s.Store.GetLogSource(channelID, alias)
 	if err != nil {
-		return LogResults{}, errors.Wrap(err, "no data for log results")
+		return store.LogSource{}, errors.Wrap(err, "error while getting log source")
+	}
+	if existing.Alias != "" {
+		return store.LogSource{}, errors.Errorf("log source %s already exists in this channel, remove it first", alias)
 	}
-	defer func() { _ = resp.Body.Close() }()
 
-	var logResults LogResults
-	if err = xml.NewDecoder(resp.Body).Decode(&logResults); err != nil {
-		return LogResults{}, errors.Wrap(err, "unexpected response")
+	if err = s.validateLogSource(source); err != nil {
+		return store.LogSource{}, err
 	}
 
-	return logResults, nil
+	if err = s.Store.SaveLogSource(source); err != nil {
+		return store.LogSource{}, errors.Wrap(err, "error in storing log source")
+	}
+	return source, nil
 }
 
-func (s *splunk) ListLogs() []string {
-	bodyString := "search index=_internal | stats count by source"
-	resp, err := s.doHTTPRequest(http.MethodPost, LogsEndpoint, strings.NewReader(bodyString))
+// ListLogSources returns log sources of the channel.
+func (s *splunk) ListLogSources(channelID string) ([]store.LogSource, error) {
+	sources, err := s.Store.GetLogSources(channelID)
 	if err != nil {
-		return []string{}
+		return nil, errors.Wrap(err, "error in listing log sources")
 	}
-	defer func() { _ = resp.Body.Close() }()
+	return sources, nil
+}
 
-	var logInformation logInfo
-	if err = xml.NewDecoder(resp.Body).Decode(&logInformation); err != nil {
-		return []string{}
+// RemoveLogSource removes log source from the channel.
+func (s *splunk) RemoveLogSource(channelID string, alias string) error {
+	if err := s.Store.DeleteLogSource(channelID, alias); err != nil {
+		return errors.Wrap(err, "error in removing log source")
 	}
-	return logInformation.getLogSources()
+	return nil
 }
 
-type logInfo struct {
-	ID             string    `xml:"id"`
-	LastUpdateTime time.Time `xml:"updated"`
-	Author         string    `xml:"author"`
-	Entries        []struct {
-		Title          string    `xml:"title"`
-		ID             string    `xml:"id"`
-		LastUpdateTime time.Time `xml:"updated"`
-		Author         string    `xml:"author"`
-	} `xml:"entry"`
+// Logs returns recent events of the log source of the channel.
+func (s *splunk) Logs(channelID string, alias string) (LogResults, error) {
+	source, err := s.Store.GetLogSource(channelID, alias)
+	if err != nil {
+		return LogResults{}, errors.Wrap(err, "error while getting log source")
+	}
+	if source.Alias == "" {
+		return LogResults{}, errors.Errorf("log source %s is not found in this channel", alias)
+	}
+
+	results, err := s.runSearch(s.User(), LogSourceSearch(source), s.GetConfiguration().ResultRows(logRows), logEarliest)
+	if err != nil {
+		return LogResults{}, errors.Wrap(err, "no log info")
+	}
+	return results, nil
 }
 
-func (l *logInfo) getLogID() (string, error) {
-	for _, e := range l.Entries {
-		if !strings.HasPrefix(e.Title, "search") {
-			continue
+// validateLogSource checks that the index exists and the search is valid on splunk server.
+// Existing indexes and valid searches are cached.
+func (s *splunk) validateLogSource(source store.LogSource) error {
+	var valid bool
+	if source.Index != "" {
+		err := s.cachedLookup(s.User(), cacheIndex, source.Index, &valid, func() error {
+			resp, err := s.doHTTPRequest(http.MethodGet, IndexesEndpoint+"/"+url.PathEscape(source.Index), nil)
+			if isStatusCode(err, http.StatusNotFound) {
+				return errors.Errorf("index %s doesn't exist or you don't have access to it", source.Index)
+			}
+			if err != nil {
+				return errors.Wrap(err, "error while checking index")
+			}
+			_ = resp.Body.Close()
+			valid = true
+			return nil
+		})
+		if err != nil {
+			return err
 		}
-		id, err := urlLastFragment(e.ID)
-		return id, err
 	}
-	return "", errors.New("not found")
+
+	search := LogSourceSearch(source)
+	return s.cachedLookup(s.User(), cacheSearch, search, &valid, func() error {
+		query := url.Values{"q": {search}}
+		resp, err := s.doHTTPRequest(http.MethodGet, SearchParserEndpoint+"?"+query.Encode(), nil)
+		if isStatusCode(err, http.StatusBadRequest) {
+			return errors.New("search is not valid SPL")
+		}
+		if err != nil {
+			return errors.Wrap(err, "error while checking search")
+		}
+		_ = resp.Body.Close()
+		valid = true
+		return nil
+	})
 }
 
-func (l *logInfo) getLogSources() []string {
-	sources := make(map[string]struct{})
-	for _, e := range l.Entries {
-		if !strings.HasPrefix(e.Title, "search") {
-			continue
+// parseLogSource parses log source definition given as index, index/sourcetype or SPL search.
+func parseLogSource(definition string) store.LogSource {
+	definition = strings.TrimSpace(definition)
+	if definition == "" {
+		return store.LogSource{}
+	}
+
+	if !strings.ContainsAny(definition, " \t\n=|") {
+		parts := strings.SplitN(definition, "/", 2)
+		if indexNamePattern.MatchString(parts[0]) {
+			source := store.LogSource{Index: parts[0]}
+			if len(parts) == 2 {
+				source.SourceType = parts[1]
+			}
+			return source
 		}
-		var source string
-		n, err := fmt.Sscanf(e.Title, "search source=\"%s", &source)
-		if err != nil || n != 1 {
-			continue
+	}
+	return store.LogSource{SPL: definition}
+}
+
+// LogSourceSearch returns splunk search of the log source.
+func LogSourceSearch(source store.LogSource) string {
+	if source.SPL != "" {
+		if strings.HasPrefix(source.SPL, "|") || strings.HasPrefix(source.SPL, "search ") {
+			return source.SPL
 		}
-		source = strings.TrimSuffix(source, "\"")
-		sources[source] = struct{}{}
+		return "search " + source.SPL
 	}
-	var res []string
-	for k := range sources {
-		res = append(res, k)
+
+	search := fmt.Sprintf("search index=%q", source.Index)
+	if source.SourceType != "" {
+		search += fmt.Sprintf(" sourcetype=%q", source.SourceType)
 	}
-	return res
+	return search
 }
@@ -22,6 +22,7 @@ func Test_splunk_Logs(t *testing.T) {
 
 	m.EXPECT().ChangeCurrentUser(gomock.Any(), gomock.Any()).Return(nil).AnyTimes()
 	m.EXPECT().RegisterUser(gomock.Any(), gomock.Any()).Return(nil).AnyTimes()
+	m.EXPECT().GetLogSource("channel", "internal").Return(store.LogSource{Alias: "internal", Index: "_internal"}, nil).AnyTimes()
 	defer ctrl.Finish()
 
 	type fields struct {
@@ -46,43 +47,51 @@ func Test_splunk_Logs(t *testing.T) {
 			s := newSplunk(nil, m)
 			s.currentUser = tt.fields.User
 
-			logs, err := s.Logs("downloaded-logs-20210119-171858.csv")
+			logs, err := s.Logs("channel", "internal")
 			is.NoError(err)
 			fmt.Println(logs)
 		})
 	}
 }
 
-func Test_splunk_ListLogs(t *testing.T) {
+func Test_splunk_AddLogSourceValidation(t *testing.T) {
 	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
 	m := mock.NewMockStore(ctrl)
+	m.EXPECT().GetLogSource("channel", "web").Return(store.LogSource{Alias: "web", Index: "main"}, nil)
 
-	m.EXPECT().ChangeCurrentUser(gomock.Any(), gomock.Any()).Return(nil).AnyTimes()
-	m.EXPECT().RegisterUser(gomock.Any(), gomock.Any()).Return(nil).AnyTimes()
-	defer ctrl.Finish()
+	s := newSplunk(nil, m)
+	_, err := s.AddLogSource("channel", "user", "bad alias!", "main")
+	assert.Error(t, err)
+	_, err = s.AddLogSource("channel", "user", "errors", " ")
+	assert.Error(t, err)
+	_, err = s.AddLogSource("channel", "user", "Web", "main/access_combined")
+	assert.EqualError(t, err, "log source web already exists in this channel, remove it first")
+}
 
-	type fields struct {
-		User store.SplunkUser
-	}
+func Test_parseLogSource(t *testing.T) {
 	tests := []struct {
-		name   string
-		fields fields
+		name       string
+		definition string
+		want       store.LogSource
 	}{
-		{
-			name: "",
-			fields: fields{
-				User: store.SplunkUser{
-					Server: server,
-					Token:  authToken,
-				},
-			},
-		},
+		{name: "index", definition: "main", want: store.LogSource{Index: "main"}},
+		{name: "index and sourcetype", definition: "main/WinEventLog:Security", want: store.LogSource{Index: "main", SourceType: "WinEventLog:Security"}},
+		{name: "SPL", definition: "index=main error | head 10", want: store.LogSource{SPL: "index=main error | head 10"}},
+		{name: "not an index", definition: "Main/access", want: store.LogSource{SPL: "Main/access"}},
+		{name: "empty", definition: "  ", want: store.LogSource{}},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			s := newSplunk(nil, m)
-			s.currentUser = tt.fields.User
-			fmt.Println(s.ListLogs())
+			assert.Equal(t, tt.want, parseLogSource(tt.definition))
 		})
 	}
 }
+
+func TestLogSourceSearch(t *testing.T) {
+	assert.Equal(t, `search index="main"`, LogSourceSearch(store.LogSource{Index: "main"}))
+	assert.Equal(t, `search index="main" sourcetype="access_combined"`, LogSourceSearch(store.LogSource{Index: "main", SourceType: "access_combined"}))
+	assert.Equal(t, "search index=main error", LogSourceSearch(store.LogSource{SPL: "index=main error"}))
+	assert.Equal(t, "search index=main", LogSourceSearch(store.LogSource{SPL: "search index=main"}))
+	assert.Equal(t, "| tstats count where index=main", LogSourceSearch(store.LogSource{SPL: "| tstats count where index=main"}))
+}
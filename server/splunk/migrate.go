@@ -0,0 +1,77 @@
+package splunk
+
+import (
+	"github.com/mattermost/mattermost-plugin-splunk/server/store"
+)
+
+// MigrateServers rewrites Splunk Cloud server URLs stored before they were normalized,
+// so users, channels, subscriptions and reports keep matching the URLs logins use now.
+// Stored users keep their authorization scheme. Migrated data is left as is, so it's safe to run on every activation.
+func (s *splunk) MigrateServers() {
+	ids, err := s.Store.GetUserIDs()
+	if err != nil {
+		s.LogError("failed to list users for server migration", "error", err.Error())
+	}
+	for _, id := range ids {
+		err = s.Store.MigrateUsers(id, func(user store.SplunkUser) store.SplunkUser {
+			if user.Type == "" {
+				user.Server, user.Type = migratedServer(user.Server)
+			}
+			return user
+		})
+		if err != nil {
+			s.LogError("failed to migrate splunk users", "userID", id, "error", err.Error())
+		}
+	}
+
+	servers, err := s.Store.GetChannelServers()
+	if err != nil {
+		s.LogError("failed to list channel servers for migration", "error", err.Error())
+	}
+	for channelID, server := range servers {
+		if migrated, _ := migratedServer(server); migrated != server {
+			if err = s.Store.SetChannelServer(channelID, migrated); err != nil {
+				s.LogError("failed to migrate channel server", "channelID", channelID, "error", err.Error())
+			}
+		}
+	}
+
+	alerts, err := s.Store.GetAlerts()
+	if err != nil {
+		s.LogError("failed to list alert subscriptions for migration", "error", err.Error())
+	}
+	for _, alert := range alerts {
+		if migrated, _ := migratedServer(alert.Server); migrated != alert.Server {
+			// the subscription is loaded again so that changes made since listing are kept
+			current, loadErr := s.Store.GetAlert(alert.ID)
+			if loadErr != nil || current.ChannelID == "" {
+				continue
+			}
+			current.Server, _ = migratedServer(current.Server)
+			if err = s.Store.UpdateAlert(current); err != nil {
+				s.LogError("failed to migrate alert subscription server", "alertID", alert.ID, "error", err.Error())
+			}
+		}
+	}
+
+	err = s.Store.MigrateReportSchedules(func(schedule store.ReportSchedule) store.ReportSchedule {
+		schedule.Server, _ = migratedServer(schedule.Server)
+		return schedule
+	})
+	if err != nil {
+		s.LogError("failed to migrate report servers", "error", err.Error())
+	}
+}
+
+// migratedServer returns the URL and type of the stored server.
+// Only Splunk Cloud URLs are changed, Enterprise URLs are kept as they were given.
+func migratedServer(server string) (string, string) {
+	if server == "" {
+		return server, ""
+	}
+	migrated, serverType, err := ServerURL(server, "", 0)
+	if err != nil || serverType != store.ServerTypeCloud {
+		return server, store.ServerTypeEnterprise
+	}
+	return migrated, serverType
+}
@@ -0,0 +1,59 @@
+package splunk
+
+import (
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/mattermost/mattermost-plugin-splunk/server/store"
+	"github.com/mattermost/mattermost-plugin-splunk/server/store/mock"
+)
+
+func Test_splunk_MigrateServers(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	is := assert.New(t)
+
+	legacyCloud := "https://acme.splunkcloud.com"
+	cloud := "https://acme.splunkcloud.com:8089"
+	enterprise := "https://splunk.example.com"
+
+	m := mock.NewMockStore(ctrl)
+	m.EXPECT().GetUserIDs().Return([]string{"user"}, nil)
+	var migratedUsers []store.SplunkUser
+	m.EXPECT().MigrateUsers("user", gomock.Any()).DoAndReturn(func(_ string, migrate func(store.SplunkUser) store.SplunkUser) error {
+		for _, u := range []store.SplunkUser{
+			{Server: legacyCloud, UserName: "admin", Token: "session"},
+			{Server: enterprise, UserName: "admin", Token: "session"},
+			{Server: "https://custom.example.com:8089", UserName: "admin", Type: store.ServerTypeCloud, AuthScheme: splunkAuthScheme},
+		} {
+			migratedUsers = append(migratedUsers, migrate(u))
+		}
+		return nil
+	})
+	m.EXPECT().GetChannelServers().Return(map[string]string{"c1": legacyCloud, "c2": enterprise}, nil)
+	m.EXPECT().SetChannelServer("c1", cloud).Return(nil)
+	m.EXPECT().GetAlerts().Return([]store.Alert{
+		{ID: "a1", ChannelID: "c1", Server: legacyCloud},
+		{ID: "a2", ChannelID: "c2", Server: enterprise},
+	}, nil)
+	m.EXPECT().GetAlert("a1").Return(store.Alert{ID: "a1", ChannelID: "c1", Server: legacyCloud, Name: "renamed"}, nil)
+	m.EXPECT().UpdateAlert(store.Alert{ID: "a1", ChannelID: "c1", Server: cloud, Name: "renamed"}).Return(nil)
+	var migratedSchedule store.ReportSchedule
+	m.EXPECT().MigrateReportSchedules(gomock.Any()).DoAndReturn(func(migrate func(store.ReportSchedule) store.ReportSchedule) error {
+		migratedSchedule = migrate(store.ReportSchedule{ID: "r1", Server: legacyCloud})
+		return nil
+	})
+
+	s := newSplunk(&fakePluginAPI{}, m)
+	s.MigrateServers()
+
+	is.Equal([]store.SplunkUser{
+		{Server: cloud, UserName: "admin", Token: "session", Type: store.ServerTypeCloud},
+		{Server: enterprise, UserName: "admin", Token: "session", Type: store.ServerTypeEnterprise},
+		{Server: "https://custom.example.com:8089", UserName: "admin", Type: store.ServerTypeCloud, AuthScheme: splunkAuthScheme},
+	}, migratedUsers)
+	is.Equal("Bearer session", authorizationHeader(migratedUsers[0]), "migrated users keep their scheme")
+	is.Equal(cloud, migratedSchedule.Server)
+}
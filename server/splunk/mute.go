@@ -0,0 +1,226 @@
+package splunk
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/mattermost/mattermost-server/v6/model"
+	"github.com/pkg/errors"
+
+	"github.com/mattermost/mattermost-plugin-splunk/server/store"
+)
+
+const (
+	// SnoozeEndpoint is called by the snooze button of alert posts
+	SnoozeEndpoint = "/alert/snooze"
+
+	// SnoozeDuration is how long the snooze button mutes the subscription
+	SnoozeDuration = time.Hour
+	// MaxMuteDuration limits muting, so subscriptions are not muted and forgotten
+	MaxMuteDuration = 30 * 24 * time.Hour
+
+	// suppressedCountTTL keeps the count of alerts not posted during the longest mute
+	suppressedCountTTL = MaxMuteDuration + 24*time.Hour
+)
+
+// MuteAlert stops posting alerts of the subscription of the channel given by its name or ID for the duration.
+// Muting a muted subscription sets the new end of the mute.
+func (s *splunk) MuteAlert(channelID string, ref string, mattermostUserID string, d time.Duration) (time.Time, error) {
+	alert, err := s.findAlert(channelID, ref)
+	if err != nil {
+		return time.Time{}, err
+	}
+	return s.muteAlert(alert, mattermostUserID, d)
+}
+
+// SnoozeAlert mutes subscription of the alert post for SnoozeDuration on behalf of the user.
+func (s *splunk) SnoozeAlert(mattermostUserID string, context map[string]interface{}) (time.Time, error) {
+	alertID, _ := context[rerunContextAlertID].(string)
+	until, err := s.snoozeAlert(mattermostUserID, alertID)
+	s.Audit(store.AuditEntry{
+		ActorID: mattermostUserID,
+		Action:  "alert/snooze",
+		Details: alertID,
+		Result:  AuditResult(err),
+	})
+	return until, err
+}
+
+func (s *splunk) snoozeAlert(mattermostUserID string, alertID string) (time.Time, error) {
+	if !s.HasPermissionTo(mattermostUserID, model.PermissionManageSystem) {
+		return time.Time{}, errors.New("you need to be a sysadmin to snooze alerts")
+	}
+
+	alert, err := s.Store.GetAlert(alertID)
+	if err != nil {
+		return time.Time{}, errors.Wrap(err, "error while getting subscription")
+	}
+	if alert.ChannelID == "" {
+		return time.Time{}, ErrAlertNotFound
+	}
+	return s.muteAlert(alert, mattermostUserID, SnoozeDuration)
+}
+
+// UnmuteAlert posts alerts of the muted subscription of the channel given by its name or ID again.
+func (s *splunk) UnmuteAlert(channelID string, ref string, mattermostUserID string) error {
+	alert, err := s.findAlert(channelID, ref)
+	if err != nil {
+		return err
+	}
+	if alert.MutedUntil == 0 {
+		return errors.New("alert is not muted")
+	}
+	return s.resumeAlert(alert, s.userMention(mattermostUserID)+" unmuted it")
+}
+
+// ResumeMutedAlerts unmutes subscriptions whose mute has ended and notifies their channels.
+// Only subscriptions in the index of muted ones are checked, it's built from all subscriptions once.
+func (s *splunk) ResumeMutedAlerts() {
+	ids, found, err := s.Store.GetMutedAlertIDs()
+	if err != nil {
+		s.LogError("failed to load muted alert subscriptions", "error", err.Error())
+		return
+	}
+	if !found {
+		if ids, err = s.indexMutedAlerts(); err != nil {
+			s.LogError("failed to index muted alert subscriptions", "error", err.Error())
+			return
+		}
+	}
+
+	now := model.GetMillis()
+	for _, id := range ids {
+		alert, loadErr := s.Store.GetAlert(id)
+		if loadErr != nil {
+			s.LogError("failed to load alert subscription", "alertID", id, "error", loadErr.Error())
+			continue
+		}
+		if alert.ChannelID == "" || alert.MutedUntil == 0 {
+			// deleted or unmuted without updating the index
+			if err = s.Store.RemoveMutedAlertID(id); err != nil {
+				s.LogWarn("failed to update muted alert subscriptions", "alertID", id, "error", err.Error())
+			}
+			continue
+		}
+		if alert.MutedUntil > now {
+			continue
+		}
+		if err = s.resumeAlert(alert, "the mute ended"); err != nil {
+			s.LogError("failed to unmute alert subscription", "alertID", id, "error", err.Error())
+		}
+	}
+}
+
+// indexMutedAlerts stores IDs of all muted subscriptions in the index of muted ones and returns them.
+func (s *splunk) indexMutedAlerts() ([]string, error) {
+	alerts, err := s.Store.GetAlerts()
+	if err != nil {
+		return nil, err
+	}
+	ids := []string{}
+	for _, alert := range alerts {
+		if alert.MutedUntil != 0 {
+			ids = append(ids, alert.ID)
+		}
+	}
+	if err = s.Store.AddMutedAlertIDs(ids); err != nil {
+		return nil, err
+	}
+	return ids, nil
+}
+
+func (s *splunk) muteAlert(alert store.Alert, mattermostUserID string, d time.Duration) (time.Time, error) {
+	if d <= 0 || d > MaxMuteDuration {
+		return time.Time{}, errors.Errorf("alerts can be muted for up to %d days", MaxMuteDuration/(24*time.Hour))
+	}
+
+	until := time.Now().Add(d)
+	if !isMuted(alert) {
+		if err := s.Store.DeleteEphemeral(suppressedCountKey(alert.ID)); err != nil {
+			s.LogWarn("failed to reset count of muted alerts", "alertID", alert.ID, "error", err.Error())
+		}
+	}
+	// the subscription is indexed first, so it can't stay muted without its mute being checked
+	if err := s.Store.AddMutedAlertIDs([]string{alert.ID}); err != nil {
+		return time.Time{}, errors.Wrap(err, "error in storing alert")
+	}
+	alert.MutedUntil = until.UnixNano() / int64(time.Millisecond)
+	if err := s.Store.UpdateAlert(alert); err != nil {
+		return time.Time{}, errors.Wrap(err, "error in storing alert")
+	}
+
+	s.notifyChannel(alert.ChannelID, fmt.Sprintf(
+		"Alert subscription %s was muted by %s until %s. "+
+			"Alerts sent to it are not posted until then, use **/splunk alert unmute %s** to post them again earlier.",
+		alertLabel(alert), s.userMention(mattermostUserID), until.UTC().Format("Jan 2, 2006 15:04 MST"), alertLabel(alert)))
+	return until, nil
+}
+
+// resumeAlert unmutes the subscription, reason tells why it's unmuted in the notice.
+func (s *splunk) resumeAlert(alert store.Alert, reason string) error {
+	alert.MutedUntil = 0
+	if err := s.Store.UpdateAlert(alert); err != nil {
+		return errors.Wrap(err, "error in storing alert")
+	}
+	if err := s.Store.RemoveMutedAlertID(alert.ID); err != nil {
+		s.LogWarn("failed to update muted alert subscriptions", "alertID", alert.ID, "error", err.Error())
+	}
+
+	var suppressed int
+	key := suppressedCountKey(alert.ID)
+	if _, err := s.Store.LoadEphemeral(key, &suppressed); err != nil {
+		s.LogWarn("failed to load count of muted alerts", "alertID", alert.ID, "error", err.Error())
+	}
+	if err := s.Store.DeleteEphemeral(key); err != nil {
+		s.LogWarn("failed to reset count of muted alerts", "alertID", alert.ID, "error", err.Error())
+	}
+
+	message := fmt.Sprintf("Alert subscription %s is posting alerts again because %s.", alertLabel(alert), reason)
+	if suppressed > 0 {
+		message += fmt.Sprintf(" %d alerts were not posted while it was muted.", suppressed)
+	}
+	s.notifyChannel(alert.ChannelID, message)
+	return nil
+}
+
+// countSuppressed counts alert of the muted subscription which was not posted.
+// The count is kept apart from the subscription, so alerts received while it's changed don't overwrite it.
+func (s *splunk) countSuppressed(alertID string) {
+	var count int
+	err := s.Store.UpdateEphemeral(suppressedCountKey(alertID), &count, suppressedCountTTL, func(found bool) error {
+		if !found {
+			count = 0
+		}
+		count++
+		return nil
+	})
+	if err != nil {
+		s.LogWarn("failed to count muted alert", "alertID", alertID, "error", err.Error())
+	}
+}
+
+func suppressedCountKey(alertID string) string {
+	return "muted_" + alertID
+}
+
+// userMention returns @username of the user, their ID if the user can't be loaded.
+func (s *splunk) userMention(mattermostUserID string) string {
+	user, err := s.GetUser(mattermostUserID)
+	if err != nil {
+		return mattermostUserID
+	}
+	return "@" + user.Username
+}
+
+// isMuted returns true if alerts of the subscription are not posted now.
+func isMuted(alert store.Alert) bool {
+	return alert.MutedUntil > model.GetMillis()
+}
+
+// alertLabel returns name of the subscription used in messages, its ID if it has no name.
+func alertLabel(alert store.Alert) string {
+	if alert.Name != "" {
+		return alert.Name
+	}
+	return alert.ID
+}
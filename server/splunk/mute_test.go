@@ -0,0 +1,113 @@
+package splunk
+
+import (
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/mattermost/mattermost-server/v6/model"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/mattermost/mattermost-plugin-splunk/server/store"
+	"github.com/mattermost/mattermost-plugin-splunk/server/store/mock"
+)
+
+func Test_splunk_MuteAlert(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	is := assert.New(t)
+
+	m := mock.NewMockStore(ctrl)
+	m.EXPECT().GetChannelAlertIDs("channel").Return([]string{"a1"}, nil).AnyTimes()
+	m.EXPECT().GetAlert("a1").Return(store.Alert{ID: "a1", ChannelID: "channel", Name: "cpu"}, nil).AnyTimes()
+	var muted store.Alert
+	m.EXPECT().DeleteEphemeral(suppressedCountKey("a1")).Return(nil)
+	m.EXPECT().AddMutedAlertIDs([]string{"a1"}).Return(nil)
+	m.EXPECT().UpdateAlert(gomock.Any()).DoAndReturn(func(alert store.Alert) error {
+		muted = alert
+		return nil
+	})
+
+	api := &fakeDirectoryAPI{users: map[string]*model.User{"admin": {Id: "admin", Username: "admin"}}}
+	s := newSplunk(api, m)
+	until, err := s.MuteAlert("channel", "cpu", "admin", 2*time.Hour)
+	is.NoError(err)
+	is.WithinDuration(time.Now().Add(2*time.Hour), until, time.Minute)
+	is.Equal(until.UnixNano()/int64(time.Millisecond), muted.MutedUntil)
+	is.True(isMuted(muted))
+	is.Len(api.posts, 1)
+	is.Contains(api.posts[0].Message, "muted by @admin")
+
+	_, err = s.MuteAlert("channel", "cpu", "admin", MaxMuteDuration+time.Hour)
+	is.Error(err)
+}
+
+func Test_splunk_NotifyMuted(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	alert := store.Alert{ID: "alert", ChannelID: "channel", MutedUntil: model.GetMillis() + time.Hour.Milliseconds()}
+	entries := map[string][]byte{suppressedCountKey("alert"): []byte("1")}
+
+	m := mock.NewMockStore(ctrl)
+	m.EXPECT().GetAlert("alert").Return(alert, nil)
+	expectEphemeralUpdates(m, entries)
+
+	api := &fakePluginAPI{}
+	s := newSplunk(api, m)
+	assert.NoError(t, s.Notify("alert", AlertActionWHPayload{SearchName: "Errors"}))
+	assert.Empty(t, api.posts)
+	assert.Equal(t, "2", string(entries[suppressedCountKey("alert")]), "subscription is not rewritten, only the count")
+}
+
+func Test_splunk_ResumeMutedAlerts(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	is := assert.New(t)
+
+	now := model.GetMillis()
+	m := mock.NewMockStore(ctrl)
+	// a2 is still muted, a4 was unmuted and a5 deleted without updating the index
+	m.EXPECT().GetMutedAlertIDs().Return([]string{"a1", "a2", "a4", "a5"}, true, nil)
+	m.EXPECT().GetAlert("a1").Return(store.Alert{ID: "a1", ChannelID: "channel", Name: "cpu", MutedUntil: now - 1}, nil)
+	m.EXPECT().GetAlert("a2").Return(store.Alert{ID: "a2", ChannelID: "channel", MutedUntil: now + time.Hour.Milliseconds()}, nil)
+	m.EXPECT().GetAlert("a4").Return(store.Alert{ID: "a4", ChannelID: "channel"}, nil)
+	m.EXPECT().GetAlert("a5").Return(store.Alert{ID: "a5"}, nil)
+	m.EXPECT().UpdateAlert(store.Alert{ID: "a1", ChannelID: "channel", Name: "cpu"}).Return(nil)
+	m.EXPECT().RemoveMutedAlertID("a1").Return(nil)
+	m.EXPECT().RemoveMutedAlertID("a4").Return(nil)
+	m.EXPECT().RemoveMutedAlertID("a5").Return(nil)
+	m.EXPECT().LoadEphemeral(suppressedCountKey("a1"), gomock.Any()).DoAndReturn(func(_ string, v interface{}) (bool, error) {
+		*v.(*int) = 3
+		return true, nil
+	})
+	m.EXPECT().DeleteEphemeral(suppressedCountKey("a1")).Return(nil)
+
+	api := &fakePluginAPI{}
+	s := newSplunk(api, m)
+	s.ResumeMutedAlerts()
+
+	is.Len(api.posts, 1)
+	is.Contains(api.posts[0].Message, "cpu is posting alerts again")
+	is.Contains(api.posts[0].Message, "3 alerts were not posted")
+}
+
+func Test_splunk_ResumeMutedAlertsBuildsIndex(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	now := model.GetMillis()
+	m := mock.NewMockStore(ctrl)
+	m.EXPECT().GetMutedAlertIDs().Return(nil, false, nil)
+	m.EXPECT().GetAlerts().Return([]store.Alert{
+		{ID: "a1", ChannelID: "channel", MutedUntil: now + time.Hour.Milliseconds()},
+		{ID: "a2", ChannelID: "channel"},
+	}, nil)
+	m.EXPECT().AddMutedAlertIDs([]string{"a1"}).Return(nil)
+	m.EXPECT().GetAlert("a1").Return(store.Alert{ID: "a1", ChannelID: "channel", MutedUntil: now + time.Hour.Milliseconds()}, nil)
+
+	api := &fakePluginAPI{}
+	s := newSplunk(api, m)
+	s.ResumeMutedAlerts()
+	assert.Empty(t, api.posts)
+}
@@ -0,0 +1,183 @@
+package splunk
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/pkg/errors"
+
+	"github.com/mattermost/mattermost-plugin-splunk/server/store"
+)
+
+// rawPayloadMaxLen limits the raw request body shown in alert posts
+const rawPayloadMaxLen = 4000
+
+// payloadDecoder decodes webhook request body into the alert payload.
+type payloadDecoder func(data []byte) (AlertActionWHPayload, error)
+
+// payloadDecoders has decoders of the supported subscription payload formats.
+var payloadDecoders = map[string]payloadDecoder{
+	store.PayloadFormatStandard:  decodeStandardPayload,
+	store.PayloadFormatTokenized: decodeTokenizedPayload,
+	store.PayloadFormatRaw:       decodeRawPayload,
+}
+
+// PayloadFormats lists the supported payload formats.
+var PayloadFormats = []string{store.PayloadFormatStandard, store.PayloadFormatTokenized, store.PayloadFormatRaw}
+
+// standardPayloadFields are top level fields of the webhook alert action payload of Splunk 8.x and 9.x
+var standardPayloadFields = []string{"result", "sid", "results_link", "owner", "app", "search_name"}
+
+// tokenizedPayloadFields maps keys commonly used for splunk tokens in custom alert actions to payload fields.
+var tokenizedPayloadFields = map[string]func(p *AlertActionWHPayload) *string{
+	"sid":          func(p *AlertActionWHPayload) *string { return &p.Sid },
+	"job.sid":      func(p *AlertActionWHPayload) *string { return &p.Sid },
+	"search_id":    func(p *AlertActionWHPayload) *string { return &p.Sid },
+	"results_link": func(p *AlertActionWHPayload) *string { return &p.ResultsLink },
+	"results.url":  func(p *AlertActionWHPayload) *string { return &p.ResultsLink },
+	"results_url":  func(p *AlertActionWHPayload) *string { return &p.ResultsLink },
+	"search_name":  func(p *AlertActionWHPayload) *string { return &p.SearchName },
+	"name":         func(p *AlertActionWHPayload) *string { return &p.SearchName },
+	"app":          func(p *AlertActionWHPayload) *string { return &p.App },
+	"app.name":     func(p *AlertActionWHPayload) *string { return &p.App },
+	"owner":        func(p *AlertActionWHPayload) *string { return &p.Owner },
+	"owner.name":   func(p *AlertActionWHPayload) *string { return &p.Owner },
+}
+
+// unresolvedToken matches splunk tokens which were sent without being replaced by their value
+var unresolvedToken = regexp.MustCompile(`^\$[\w.]+\$$`)
+
+// DecodeAlertPayload decodes webhook request body with the payload format of the alert subscription.
+// Returns ErrAlertNotFound if there is no such subscription.
+func (s *splunk) DecodeAlertPayload(alertID string, data []byte) (AlertActionWHPayload, error) {
+	alert, err := s.Store.GetAlert(alertID)
+	if err != nil {
+		return AlertActionWHPayload{}, errors.Wrap(err, "error while getting subscription")
+	}
+	if alert.ChannelID == "" {
+		return AlertActionWHPayload{}, ErrAlertNotFound
+	}
+
+	return decodePayload(alert.PayloadFormat, data)
+}
+
+// decodePayload decodes data with the decoder of the format, empty format is standard.
+func decodePayload(format string, data []byte) (AlertActionWHPayload, error) {
+	if format == "" {
+		format = store.PayloadFormatStandard
+	}
+	decode, ok := payloadDecoders[format]
+	if !ok {
+		return AlertActionWHPayload{}, errors.Errorf("unknown payload format %q", format)
+	}
+	return decode(data)
+}
+
+// SetAlertPayloadFormat sets payload format of the alert subscribed in the channel given by its name or ID.
+func (s *splunk) SetAlertPayloadFormat(channelID string, ref string, format string) error {
+	format = strings.ToLower(format)
+	if _, ok := payloadDecoders[format]; !ok {
+		return errors.Errorf("unknown payload format %s, use one of %s", format, strings.Join(PayloadFormats, ", "))
+	}
+
+	alert, err := s.findAlert(channelID, ref)
+	if err != nil {
+		return err
+	}
+
+	alert.PayloadFormat = format
+	if format == store.PayloadFormatStandard {
+		alert.PayloadFormat = ""
+	}
+	if err = s.Store.UpdateAlert(alert); err != nil {
+		return errors.Wrap(err, "error in storing alert")
+	}
+	return nil
+}
+
+// decodeStandardPayload decodes the webhook alert action payload.
+// Payloads without any of its fields are rejected instead of being posted empty.
+func decodeStandardPayload(data []byte) (AlertActionWHPayload, error) {
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal(data, &fields); err != nil {
+		return AlertActionWHPayload{}, errors.Wrap(err, "payload is not a JSON object")
+	}
+	known := false
+	for _, field := range standardPayloadFields {
+		if _, ok := fields[field]; ok {
+			known = true
+			break
+		}
+	}
+	if !known {
+		return AlertActionWHPayload{}, errors.New("payload is not a Splunk webhook alert action payload, set tokenized or raw payload format for the subscription")
+	}
+
+	var payload AlertActionWHPayload
+	if err := json.Unmarshal(data, &payload); err != nil {
+		return AlertActionWHPayload{}, errors.Wrap(err, "invalid webhook alert action payload")
+	}
+	return payload, nil
+}
+
+// decodeTokenizedPayload decodes flat JSON object built by custom alert actions from splunk tokens,
+// e.g. {"search_name": "$name$", "results_link": "$results.url$", "host": "$result.host$"}.
+// Fields other than the search ones are kept as result fields, result. prefix is removed from their keys.
+func decodeTokenizedPayload(data []byte) (AlertActionWHPayload, error) {
+	var fields map[string]interface{}
+	if err := json.Unmarshal(data, &fields); err != nil {
+		return AlertActionWHPayload{}, errors.Wrap(err, "payload is not a JSON object")
+	}
+
+	payload := AlertActionWHPayload{Result: wHFirstResult{Fields: map[string]interface{}{}}}
+	for key, value := range fields {
+		if s, ok := value.(string); ok && unresolvedToken.MatchString(s) {
+			continue
+		}
+		// nested result object is the same as result. keys
+		if result, ok := value.(map[string]interface{}); ok && key == "result" {
+			for name, v := range result {
+				payload.Result.Fields[name] = v
+			}
+			continue
+		}
+		if field, ok := tokenizedPayloadFields[strings.ToLower(key)]; ok {
+			*field(&payload) = fmt.Sprint(value)
+			continue
+		}
+		payload.Result.Fields[strings.TrimPrefix(key, "result.")] = value
+	}
+	payload.Result.SourceType = payload.Result.Field("sourcetype")
+	payload.Result.Count = payload.Result.Field("count")
+	return payload, nil
+}
+
+// decodeRawPayload keeps the request body as it is.
+// Top level fields of JSON objects are still available as result fields.
+func decodeRawPayload(data []byte) (AlertActionWHPayload, error) {
+	raw := string(bytes.TrimSpace(data))
+	if raw == "" {
+		return AlertActionWHPayload{}, errors.New("payload is empty")
+	}
+
+	payload := AlertActionWHPayload{Raw: raw}
+	var fields map[string]interface{}
+	if json.Unmarshal(data, &fields) == nil {
+		payload.Result.Fields = fields
+		payload.Result.SourceType = payload.Result.Field("sourcetype")
+		payload.Result.Count = payload.Result.Field("count")
+	}
+	return payload, nil
+}
+
+// rawPayloadBlock formats raw payload as a code block for alert posts.
+func rawPayloadBlock(raw string) string {
+	lang := ""
+	if json.Valid([]byte(raw)) {
+		lang = "json"
+	}
+	return fmt.Sprintf("```%s\n%s\n```", lang, truncate(rawPayloadMaxLen, raw))
+}
@@ -0,0 +1,113 @@
+package splunk
+
+import (
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/mattermost/mattermost-plugin-splunk/server/store"
+	"github.com/mattermost/mattermost-plugin-splunk/server/store/mock"
+)
+
+func Test_decodePayload(t *testing.T) {
+	tests := []struct {
+		name    string
+		format  string
+		body    string
+		want    AlertActionWHPayload
+		fields  map[string]string
+		wantErr bool
+	}{
+		{
+			name:   "standard",
+			body:   `{"sid": "rt_scheduler", "search_name": "Errors", "app": "search", "result": {"host": "web-1", "count": "3"}}`,
+			want:   AlertActionWHPayload{Sid: "rt_scheduler", SearchName: "Errors", App: "search"},
+			fields: map[string]string{"host": "web-1"},
+		},
+		{
+			name:    "standard rejects other payloads",
+			body:    `{"host": "web-1"}`,
+			wantErr: true,
+		},
+		{
+			name:    "standard rejects invalid JSON",
+			body:    `host=web-1`,
+			wantErr: true,
+		},
+		{
+			name:   "tokenized",
+			format: store.PayloadFormatTokenized,
+			body:   `{"name": "Errors", "results.url": "http://splunk/results", "job.sid": "$job.sid$", "result.host": "web-1", "region": "eu", "result": {"count": "3"}}`,
+			want:   AlertActionWHPayload{SearchName: "Errors", ResultsLink: "http://splunk/results"},
+			fields: map[string]string{"host": "web-1", "region": "eu", "count": "3"},
+		},
+		{
+			name:   "raw JSON",
+			format: store.PayloadFormatRaw,
+			body:   ` {"host": "web-1"} `,
+			want:   AlertActionWHPayload{Raw: `{"host": "web-1"}`},
+			fields: map[string]string{"host": "web-1"},
+		},
+		{
+			name:   "raw text",
+			format: store.PayloadFormatRaw,
+			body:   "disk full on web-1",
+			want:   AlertActionWHPayload{Raw: "disk full on web-1"},
+		},
+		{
+			name:    "raw empty",
+			format:  store.PayloadFormatRaw,
+			body:    " ",
+			wantErr: true,
+		},
+		{
+			name:    "unknown format",
+			format:  "xml",
+			body:    `{}`,
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := decodePayload(tt.format, []byte(tt.body))
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tt.want.Sid, got.Sid)
+			assert.Equal(t, tt.want.SearchName, got.SearchName)
+			assert.Equal(t, tt.want.ResultsLink, got.ResultsLink)
+			assert.Equal(t, tt.want.App, got.App)
+			assert.Equal(t, tt.want.Raw, got.Raw)
+			for name, value := range tt.fields {
+				assert.Equal(t, value, got.Result.Field(name), name)
+			}
+		})
+	}
+}
+
+func Test_splunk_SetAlertPayloadFormat(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	m := mock.NewMockStore(ctrl)
+	m.EXPECT().GetChannelAlertIDs("channel").Return([]string{"a1"}, nil).AnyTimes()
+	m.EXPECT().GetAlert("a1").Return(store.Alert{ID: "a1", ChannelID: "channel", Name: "cpu"}, nil).AnyTimes()
+	m.EXPECT().UpdateAlert(store.Alert{ID: "a1", ChannelID: "channel", Name: "cpu", PayloadFormat: store.PayloadFormatRaw}).Return(nil)
+	m.EXPECT().UpdateAlert(store.Alert{ID: "a1", ChannelID: "channel", Name: "cpu"}).Return(nil)
+
+	s := newSplunk(&fakePluginAPI{}, m)
+	assert.NoError(t, s.SetAlertPayloadFormat("channel", "cpu", "RAW"))
+	assert.NoError(t, s.SetAlertPayloadFormat("channel", "a1", store.PayloadFormatStandard), "standard is stored as default")
+	assert.Error(t, s.SetAlertPayloadFormat("channel", "a1", "xml"))
+}
+
+func Test_splunk_alertMessageRawPayload(t *testing.T) {
+	s := newSplunk(&fakePluginAPI{}, nil)
+
+	assert.Equal(t, "New alert action received\n\n```json\n{\"host\": \"web-1\"}\n```",
+		s.alertMessage(store.Alert{ID: "alert"}, AlertActionWHPayload{Raw: `{"host": "web-1"}`}))
+}
@@ -0,0 +1,159 @@
+package splunk
+
+import (
+	"fmt"
+	"math"
+	"time"
+
+	"github.com/mattermost/mattermost-server/v6/model"
+	"github.com/pkg/errors"
+)
+
+const (
+	globalRateLimitKey = "ratelimit_global"
+
+	// rateLimitWindow is the period in which bucket of any size refills completely.
+	rateLimitWindow = time.Minute
+)
+
+// rateLimitBucket is token bucket state persisted in KVStore,
+// so that all nodes of the cluster share the same limits.
+type rateLimitBucket struct {
+	Tokens    float64
+	UpdatedAt time.Time
+	// Tripped is set when bucket ran out of tokens and suppression notice was posted,
+	// it's cleared once the bucket refills completely, i.e. the alert storm is over.
+	Tripped bool
+}
+
+// take refills bucket for the elapsed time and tries to take a single token from it.
+// limit is both bucket capacity and number of tokens refilled per rateLimitWindow.
+// If there are no tokens left returns false and time after which token will be available.
+func (b *rateLimitBucket) take(now time.Time, limit int) (bool, time.Duration) {
+	capacity := float64(limit)
+	rate := capacity / float64(rateLimitWindow)
+	b.Tokens = math.Min(capacity, b.Tokens+float64(now.Sub(b.UpdatedAt))*rate)
+	b.UpdatedAt = now
+	if b.Tokens >= capacity {
+		b.Tripped = false
+	}
+	if b.Tokens >= 1 {
+		b.Tokens--
+		return true, 0
+	}
+	return false, time.Duration((1 - b.Tokens) / rate)
+}
+
+func rateLimitKey(alertID string) string {
+	return fmt.Sprintf("ratelimit_%s", alertID)
+}
+
+// AllowAlert checks per subscription and global webhook rate limits for the alert.
+// Limits are numbers of alerts per minute, zero disables the limit.
+// Returns zero if delivery is allowed and time after which it should be retried otherwise.
+// Single notice is posted each time a limit trips: to the alert's channel for the subscription limit
+// and to the errors channel for the global limit. Buckets are updated atomically, so the limits hold across the cluster.
+func (s *splunk) AllowAlert(alertID string, perSubscription int, global int) (time.Duration, error) {
+	channelID, err := s.Store.GetChannelIDForAlert(alertID)
+	if err != nil {
+		return 0, errors.Wrap(err, "error while getting subscription")
+	}
+	if channelID == "" {
+		return 0, nil
+	}
+
+	limits := []struct {
+		key    string
+		limit  int
+		notice func(limit int)
+	}{
+		{rateLimitKey(alertID), perSubscription, func(limit int) {
+			s.postAlertStormNotice(channelID, fmt.Sprintf("more than %d alerts per minute were received for this subscription", limit))
+		}},
+		{globalRateLimitKey, global, func(limit int) {
+			s.postGlobalAlertStormNotice(fmt.Sprintf("more than %d alerts per minute were received across all subscriptions", limit))
+		}},
+	}
+
+	now := time.Now()
+	taken := make(map[string]int)
+	for _, l := range limits {
+		if l.limit <= 0 {
+			continue
+		}
+
+		var bucket rateLimitBucket
+		var allowed, tripped bool
+		var retryAfter time.Duration
+		err = s.Store.UpdateEphemeral(l.key, &bucket, rateLimitWindow, func(found bool) error {
+			if !found {
+				bucket = rateLimitBucket{Tokens: float64(l.limit), UpdatedAt: now}
+			}
+			allowed, retryAfter = bucket.take(now, l.limit)
+			tripped = !allowed && !bucket.Tripped
+			if tripped {
+				bucket.Tripped = true
+			}
+			return nil
+		})
+		if err != nil {
+			return 0, errors.Wrap(err, "error while updating rate limit")
+		}
+
+		if allowed {
+			taken[l.key] = l.limit
+			continue
+		}
+		if tripped {
+			l.notice(l.limit)
+		}
+		// the alert is not delivered, so it doesn't count towards the limits it passed
+		for key, limit := range taken {
+			s.returnRateLimitToken(key, limit)
+		}
+		return retryAfter, nil
+	}
+	return 0, nil
+}
+
+// returnRateLimitToken puts back token taken from the bucket of the key.
+func (s *splunk) returnRateLimitToken(key string, limit int) {
+	var bucket rateLimitBucket
+	err := s.Store.UpdateEphemeral(key, &bucket, rateLimitWindow, func(found bool) error {
+		if found {
+			bucket.Tokens = math.Min(float64(limit), bucket.Tokens+1)
+		}
+		return nil
+	})
+	if err != nil {
+		s.LogWarn("failed to return rate limit token", "error", err.Error())
+	}
+}
+
+func (s *splunk) postAlertStormNotice(channelID string, reason string) {
+	_, err := s.CreatePost(&model.Post{
+		UserId:    s.BotUser(),
+		ChannelId: channelID,
+		Message:   alertStormMessage(reason),
+	})
+	if err != nil {
+		s.LogError("error creating alert storm notice", "error", err.Error())
+	}
+}
+
+// postGlobalAlertStormNotice posts the notice to the errors channel, it affects all subscription channels.
+func (s *splunk) postGlobalAlertStormNotice(reason string) {
+	s.LogWarn("alert storm suppressed", "reason", reason)
+	channelID, err := s.errorsChannelID()
+	if err != nil {
+		s.LogWarn("failed to find errors channel", "error", err.Error())
+		return
+	}
+	if channelID != "" {
+		s.postAlertStormNotice(channelID, reason)
+	}
+}
+
+func alertStormMessage(reason string) string {
+	return fmt.Sprintf("Alert storm suppressed: %s. Further alerts are dropped until the rate goes down.", reason)
+}
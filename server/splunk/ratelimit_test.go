@@ -0,0 +1,120 @@
+package splunk
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/mattermost/mattermost-server/v6/model"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/mattermost/mattermost-plugin-splunk/server/config"
+	"github.com/mattermost/mattermost-plugin-splunk/server/store/mock"
+)
+
+func Test_rateLimitBucket_take(t *testing.T) {
+	is := assert.New(t)
+	now := time.Now()
+	b := rateLimitBucket{Tokens: 2, UpdatedAt: now}
+
+	allowed, _ := b.take(now, 2)
+	is.True(allowed)
+	allowed, _ = b.take(now, 2)
+	is.True(allowed)
+
+	allowed, retryAfter := b.take(now, 2)
+	is.False(allowed)
+	is.Equal(30*time.Second, retryAfter)
+
+	allowed, _ = b.take(now.Add(30*time.Second), 2)
+	is.True(allowed)
+
+	allowed, _ = b.take(now.Add(time.Hour), 2)
+	is.True(allowed)
+	is.Equal(float64(1), b.Tokens)
+}
+
+func Test_rateLimitBucket_takeKeepsTripUntilRefilled(t *testing.T) {
+	now := time.Now()
+	b := rateLimitBucket{Tokens: 0, UpdatedAt: now, Tripped: true}
+
+	allowed, _ := b.take(now.Add(30*time.Second), 2)
+	assert.True(t, allowed)
+	assert.True(t, b.Tripped, "storm is not over while tokens are refilled one by one")
+
+	b.take(now.Add(3*time.Minute), 2)
+	assert.False(t, b.Tripped)
+}
+
+// expectEphemeralUpdates makes UpdateEphemeral of the mock store work on entries kept in memory.
+func expectEphemeralUpdates(m *mock.MockStore, entries map[string][]byte) {
+	m.EXPECT().UpdateEphemeral(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).DoAndReturn(
+		func(key string, v interface{}, _ time.Duration, update func(bool) error) error {
+			data, found := entries[key]
+			if found {
+				if err := json.Unmarshal(data, v); err != nil {
+					return err
+				}
+			}
+			if err := update(found); err != nil {
+				return err
+			}
+			data, err := json.Marshal(v)
+			entries[key] = data
+			return err
+		}).AnyTimes()
+}
+
+func Test_splunk_AllowAlert(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	is := assert.New(t)
+
+	m := mock.NewMockStore(ctrl)
+	m.EXPECT().GetChannelIDForAlert("alert").Return("channel", nil).AnyTimes()
+	expectEphemeralUpdates(m, make(map[string][]byte))
+
+	api := &fakePluginAPI{}
+	s := newSplunk(api, m)
+	for i := 0; i < 2; i++ {
+		retryAfter, err := s.AllowAlert("alert", 2, 0)
+		is.NoError(err)
+		is.Zero(retryAfter)
+	}
+	for i := 0; i < 3; i++ {
+		retryAfter, err := s.AllowAlert("alert", 2, 0)
+		is.NoError(err)
+		is.NotZero(retryAfter)
+	}
+	is.Len(api.posts, 1, "single notice is posted during the storm")
+	is.Equal("channel", api.posts[0].ChannelId)
+}
+
+func Test_splunk_AllowAlertGlobalLimit(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	is := assert.New(t)
+
+	m := mock.NewMockStore(ctrl)
+	m.EXPECT().GetChannelIDForAlert("alert").Return("channel", nil).AnyTimes()
+	entries := make(map[string][]byte)
+	expectEphemeralUpdates(m, entries)
+
+	api := &fakeDirectoryAPI{channels: map[string]*model.Channel{"team/errors": {Id: "errors"}}}
+	api.config = &config.Config{ErrorsChannel: "team/errors"}
+	s := newSplunk(api, m)
+	retryAfter, err := s.AllowAlert("alert", 5, 1)
+	is.NoError(err)
+	is.Zero(retryAfter)
+	retryAfter, err = s.AllowAlert("alert", 5, 1)
+	is.NoError(err)
+	is.NotZero(retryAfter)
+
+	is.Len(api.posts, 1)
+	is.Equal("errors", api.posts[0].ChannelId, "global limit is reported in the errors channel")
+
+	var bucket rateLimitBucket
+	is.NoError(json.Unmarshal(entries[rateLimitKey("alert")], &bucket))
+	is.InDelta(4, bucket.Tokens, 0.01, "dropped alert doesn't count towards subscription limit")
+}
@@ -0,0 +1,56 @@
+package splunk
+
+import (
+	"strings"
+)
+
+var markdownCellReplacer = strings.NewReplacer("|", "\\|", "\r\n", " ", "\n", " ")
+
+// MarkdownTable renders results as markdown table.
+// Columns are ordered by the first appearance of the field in results.
+// Returns empty string if there are no results.
+func (l LogResults) MarkdownTable() string {
+	fieldNames := make(map[string]int)
+	var header []string
+	for _, result := range l.Results {
+		for _, field := range result.Fields {
+			if _, ok := fieldNames[field.Name]; !ok {
+				fieldNames[field.Name] = len(header)
+				header = append(header, field.Name)
+			}
+		}
+	}
+	if len(header) == 0 {
+		return ""
+	}
+
+	var sb strings.Builder
+	writeRow := func(cells []string) {
+		sb.WriteString(markdownRow(cells))
+	}
+
+	writeRow(header)
+	sb.WriteString("|" + strings.Repeat(" :- |", len(header)) + "\n")
+	var fields = make([]string, len(header))
+	for _, result := range l.Results {
+		for i := range fields {
+			fields[i] = ""
+		}
+		for _, field := range result.Fields {
+			fields[fieldNames[field.Name]] = field.Value.Text
+		}
+		writeRow(fields)
+	}
+	return sb.String()
+}
+
+// markdownRow renders cells as a row of markdown table.
+func markdownRow(cells []string) string {
+	var sb strings.Builder
+	sb.WriteString("|")
+	for _, cell := range cells {
+		sb.WriteString(" " + markdownCellReplacer.Replace(cell) + " |")
+	}
+	sb.WriteString("\n")
+	return sb.String()
+}
@@ -0,0 +1,24 @@
+package splunk
+
+import (
+	"encoding/xml"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLogResults_MarkdownTable(t *testing.T) {
+	data := `<results>
+<result><field k="host"><value><text>web-1</text></value></field><field k="count"><value><text>10</text></value></field></result>
+<result><field k="host"><value><text>web|2</text></value></field><field k="status"><value><text>500</text></value></field></result>
+</results>`
+
+	var results LogResults
+	assert.NoError(t, xml.Unmarshal([]byte(data), &results))
+	assert.Equal(t, "| host | count | status |\n"+
+		"| :- | :- | :- |\n"+
+		"| web-1 | 10 |  |\n"+
+		"| web\\|2 |  | 500 |\n", results.MarkdownTable())
+
+	assert.Equal(t, "", LogResults{}.MarkdownTable())
+}
@@ -0,0 +1,222 @@
+package splunk
+
+import (
+	"encoding/xml"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/mattermost/mattermost-server/v6/model"
+	"github.com/pkg/errors"
+
+	"github.com/mattermost/mattermost-plugin-splunk/server/store"
+)
+
+const (
+	// PDFGenEndpoint endpoint for rendering reports as PDF
+	PDFGenEndpoint = "/services/pdfgen/render"
+
+	// reportRows is the maximum number of result rows shown in report post.
+	reportRows = 50
+	// maxReportPDFSize is the maximum size of the report PDF attached to the post.
+	maxReportPDFSize = 20 * 1024 * 1024
+)
+
+// ScheduleReport validates that saved report exists on the current user's server
+// and schedules its delivery.
+func (s *splunk) ScheduleReport(schedule store.ReportSchedule) (store.ReportSchedule, error) {
+	if err := s.checkSavedSearchIn(s.User(), "", "", schedule.Report); err != nil {
+		return store.ReportSchedule{}, err
+	}
+
+	schedule.ID = uuid.New().String()
+	schedule.Server = s.User().Server
+	schedule.UserName = s.User().UserName
+	schedule.NextRun = nextReportRun(schedule, time.Now())
+	if err := s.Store.SaveReportSchedule(schedule); err != nil {
+		return store.ReportSchedule{}, errors.Wrap(err, "error in storing report")
+	}
+	return schedule, nil
+}
+
+// ListReports returns reports scheduled in the channel.
+func (s *splunk) ListReports(channelID string) ([]store.ReportSchedule, error) {
+	schedules, err := s.Store.GetReportSchedules()
+	if err != nil {
+		return nil, errors.Wrap(err, "error in listing reports")
+	}
+
+	var res []store.ReportSchedule
+	for _, schedule := range schedules {
+		if schedule.ChannelID == channelID {
+			res = append(res, schedule)
+		}
+	}
+	return res, nil
+}
+
+// DeleteReport removes report scheduled in the channel.
+func (s *splunk) DeleteReport(channelID string, scheduleID string) (store.ReportSchedule, error) {
+	schedules, err := s.ListReports(channelID)
+	if err != nil {
+		return store.ReportSchedule{}, err
+	}
+
+	for _, schedule := range schedules {
+		if schedule.ID != scheduleID {
+			continue
+		}
+		if err = s.Store.DeleteReportSchedule(scheduleID); err != nil {
+			return store.ReportSchedule{}, errors.Wrap(err, "error in deleting report")
+		}
+		return schedule, nil
+	}
+	return store.ReportSchedule{}, errors.New("report was not found in this channel")
+}
+
+// RunDueReports delivers all reports which are due.
+func (s *splunk) RunDueReports() {
+	schedules, err := s.Store.GetReportSchedules()
+	if err != nil {
+		s.LogError("failed to load scheduled reports", "error", err.Error())
+		return
+	}
+
+	now := time.Now()
+	for _, schedule := range schedules {
+		if schedule.NextRun.After(now) {
+			continue
+		}
+
+		if err = s.deliverReport(schedule); err != nil {
+			s.ReportDeliveryError(DeliveryError{
+				CorrelationID: model.NewId(),
+				Reason:        fmt.Sprintf("Failed to deliver scheduled report %q", schedule.Report),
+				Err:           err,
+			})
+		}
+
+		// only the next run is stored, so the schedule isn't restored if it was deleted during delivery
+		if err = s.Store.SetReportNextRun(schedule.ID, nextReportRun(schedule, now)); err != nil {
+			s.LogError("failed to save scheduled report", "id", schedule.ID, "error", err.Error())
+		}
+	}
+}
+
+func (s *splunk) deliverReport(schedule store.ReportSchedule) error {
+	user, err := s.Store.User(schedule.CreatorID, schedule.Server, schedule.UserName)
+	if err != nil {
+		return errors.Wrap(err, "report creator is not authorized")
+	}
+
+	results, err := s.runSavedSearch(user, schedule.Report, s.GetConfiguration().ResultRows(reportRows))
+	if err != nil {
+		return errors.Wrap(err, "error while running report")
+	}
+
+	message := fmt.Sprintf("#### Splunk report: %s\n", schedule.Report)
+	if table := results.MarkdownTable(); table != "" {
+		message += table
+	} else {
+		message += "Report has no results"
+	}
+
+	post := &model.Post{
+		UserId:    s.BotUser(),
+		ChannelId: schedule.ChannelID,
+		Message:   message,
+	}
+
+	// PDF rendering is not available on every deployment, report is posted without it then
+	if pdf, pdfErr := s.renderReportPDF(user, schedule.Report); pdfErr != nil {
+		s.LogDebug("failed to render report PDF", "report", schedule.Report, "error", pdfErr.Error())
+	} else {
+		info, uploadErr := s.UploadFile(pdf, schedule.ChannelID, schedule.Report+".pdf")
+		if uploadErr != nil {
+			s.LogWarn("failed to upload report PDF", "report", schedule.Report, "error", uploadErr.Error())
+		} else {
+			post.FileIds = []string{info.Id}
+		}
+	}
+
+	if _, err = s.CreatePost(post); err != nil {
+		return errors.Wrap(err, "error creating post for report")
+	}
+	return nil
+}
+
+// runSavedSearch runs saved search synchronously and returns first count results.
+func (s *splunk) runSavedSearch(user store.SplunkUser, name string, count int) (LogResults, error) {
+	return s.runSearch(user, fmt.Sprintf("| savedsearch \"%s\"", strings.ReplaceAll(name, `"`, `\"`)), count, "")
+}
+
+// runSearch runs search synchronously and returns first count results
+// of events since earliest, all time is searched if earliest is empty.
+func (s *splunk) runSearch(user store.SplunkUser, search string, count int, earliest string) (LogResults, error) {
+	body := url.Values{
+		"search":    {search},
+		"exec_mode": {"oneshot"},
+		"count":     {strconv.Itoa(count)},
+	}
+	if earliest != "" {
+		body.Set("earliest_time", earliest)
+	}
+	resp, err := s.doHTTPRequestAs(user, http.MethodPost, LogsEndpoint, strings.NewReader(body.Encode()))
+	if err != nil {
+		return LogResults{}, errors.Wrap(err, "no data for search results")
+	}
+	defer func() { _ = resp.Body.Close() }()
+
+	var results LogResults
+	if err = xml.NewDecoder(resp.Body).Decode(&results); err != nil {
+		return LogResults{}, errors.Wrap(err, "unexpected response")
+	}
+	return results, nil
+}
+
+func (s *splunk) renderReportPDF(user store.SplunkUser, name string) ([]byte, error) {
+	query := url.Values{"input-report": {name}}
+	resp, err := s.doHTTPRequestAs(user, http.MethodGet, PDFGenEndpoint+"?"+query.Encode(), nil)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = resp.Body.Close() }()
+
+	data, err := ioutil.ReadAll(io.LimitReader(resp.Body, maxReportPDFSize+1))
+	if err != nil {
+		return nil, errors.Wrap(err, "error while reading PDF")
+	}
+	if len(data) > maxReportPDFSize {
+		return nil, errors.New("PDF is too large")
+	}
+	if !strings.HasPrefix(resp.Header.Get("Content-Type"), "application/pdf") {
+		return nil, errors.New("unexpected response")
+	}
+	return data, nil
+}
+
+// nextReportRun returns first delivery time of the report after the given time.
+func nextReportRun(schedule store.ReportSchedule, after time.Time) time.Time {
+	loc, err := time.LoadLocation(schedule.Location)
+	if err != nil {
+		loc = time.UTC
+	}
+
+	t := after.In(loc)
+	next := time.Date(t.Year(), t.Month(), t.Day(), schedule.Hour, schedule.Minute, 0, 0, loc)
+	days := 1
+	if schedule.Interval == store.ReportWeekly {
+		days = 7
+		next = next.AddDate(0, 0, (int(schedule.Weekday)-int(next.Weekday())+7)%7)
+	}
+	if !next.After(after) {
+		next = next.AddDate(0, 0, days)
+	}
+	return next
+}
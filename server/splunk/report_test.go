@@ -0,0 +1,79 @@
+package splunk
+
+import (
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/mattermost/mattermost-plugin-splunk/server/store"
+	"github.com/mattermost/mattermost-plugin-splunk/server/store/mock"
+)
+
+func Test_nextReportRun(t *testing.T) {
+	// Wednesday
+	now := time.Date(2021, time.March, 10, 12, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name     string
+		schedule store.ReportSchedule
+		want     time.Time
+	}{
+		{
+			name:     "daily later today",
+			schedule: store.ReportSchedule{Interval: store.ReportDaily, Hour: 15, Minute: 30},
+			want:     time.Date(2021, time.March, 10, 15, 30, 0, 0, time.UTC),
+		},
+		{
+			name:     "daily tomorrow",
+			schedule: store.ReportSchedule{Interval: store.ReportDaily, Hour: 12},
+			want:     time.Date(2021, time.March, 11, 12, 0, 0, 0, time.UTC),
+		},
+		{
+			name:     "weekly next monday",
+			schedule: store.ReportSchedule{Interval: store.ReportWeekly, Weekday: time.Monday, Hour: 9},
+			want:     time.Date(2021, time.March, 15, 9, 0, 0, 0, time.UTC),
+		},
+		{
+			name:     "weekly today",
+			schedule: store.ReportSchedule{Interval: store.ReportWeekly, Weekday: time.Wednesday, Hour: 13},
+			want:     time.Date(2021, time.March, 10, 13, 0, 0, 0, time.UTC),
+		},
+		{
+			name:     "weekly passed today",
+			schedule: store.ReportSchedule{Interval: store.ReportWeekly, Weekday: time.Wednesday, Hour: 9},
+			want:     time.Date(2021, time.March, 17, 9, 0, 0, 0, time.UTC),
+		},
+		{
+			name:     "time zone",
+			schedule: store.ReportSchedule{Interval: store.ReportDaily, Hour: 9, Location: "America/New_York"},
+			want:     time.Date(2021, time.March, 10, 14, 0, 0, 0, time.UTC),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.True(t, tt.want.Equal(nextReportRun(tt.schedule, now)), "got %v", nextReportRun(tt.schedule, now))
+		})
+	}
+}
+
+func Test_splunk_RunDueReports(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	now := time.Now()
+	due := store.ReportSchedule{ID: "due", Report: "errors", Interval: store.ReportDaily, Location: "UTC", NextRun: now.Add(-time.Minute)}
+	later := store.ReportSchedule{ID: "later", Report: "errors", Interval: store.ReportDaily, Location: "UTC", NextRun: now.Add(time.Hour)}
+
+	m := mock.NewMockStore(ctrl)
+	m.EXPECT().GetReportSchedules().Return([]store.ReportSchedule{due, later}, nil)
+	m.EXPECT().User(gomock.Any(), gomock.Any(), gomock.Any()).Return(store.SplunkUser{}, errors.New("not found"))
+	m.EXPECT().SetReportNextRun("due", gomock.Any()).Return(nil).
+		Do(func(_ string, next time.Time) { assert.True(t, next.After(now)) })
+
+	s := newSplunk(&fakePluginAPI{}, m)
+	s.RunDueReports()
+}
@@ -0,0 +1,137 @@
+package splunk
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/mattermost/mattermost-server/v6/model"
+	"github.com/pkg/errors"
+
+	"github.com/mattermost/mattermost-plugin-splunk/server/store"
+)
+
+const (
+	// RerunEndpoint is called by the re-run search button of alert posts
+	RerunEndpoint = "/alert/rerun"
+
+	// rerunRows is the number of result rows shown after re-running the search
+	rerunRows = 10
+
+	// post action context keys
+	rerunContextAlertID    = "alert_id"
+	rerunContextSearchName = "search_name"
+)
+
+// alertAttachment creates alert post attachment with the snooze button and the re-run search button
+// for alerts of saved searches, unless it's hidden. text is shown above the buttons, it contains results of the last re-run.
+func (s *splunk) alertAttachment(alertID string, searchName string, text string) *model.SlackAttachment {
+	context := map[string]interface{}{
+		rerunContextAlertID:    alertID,
+		rerunContextSearchName: searchName,
+	}
+
+	var actions []*model.PostAction
+	if searchName != "" && !s.GetConfiguration().AlertHideRerunButton {
+		actions = append(actions, &model.PostAction{
+			Id:   "rerun",
+			Name: "Re-run search",
+			Type: model.PostActionTypeButton,
+			Integration: &model.PostActionIntegration{
+				URL:     s.pluginURL(RerunEndpoint),
+				Context: context,
+			},
+		})
+	}
+	actions = append(actions, &model.PostAction{
+		Id:   "snooze",
+		Name: "Snooze for 1 hour",
+		Type: model.PostActionTypeButton,
+		Integration: &model.PostActionIntegration{
+			URL:     s.pluginURL(SnoozeEndpoint),
+			Context: context,
+		},
+	})
+
+	return &model.SlackAttachment{
+		Color:   s.GetConfiguration().AlertColor,
+		Text:    text,
+		Actions: actions,
+	}
+}
+
+// RerunAlertSearch runs saved search of the alert post on behalf of the user
+// and updates the post with the current results.
+// The alert and the search are taken from the stored post, so only searches of alert posts can be run.
+func (s *splunk) RerunAlertSearch(userID string, postID string) error {
+	post, err := s.GetPost(postID)
+	if err != nil {
+		return err
+	}
+	if post.UserId != s.BotUser() {
+		return errors.New("post is not an alert post")
+	}
+	if !s.HasPermissionToChannel(userID, post.ChannelId, model.PermissionReadChannel) {
+		return errors.New("you don't have access to the channel of the alert post")
+	}
+
+	alertID, searchName := alertPostSearch(post)
+	if searchName == "" {
+		return errors.New("alert post has no saved search")
+	}
+
+	user, err := s.channelUser(userID, post.ChannelId)
+	if err != nil {
+		return errors.New("you need to log in to Splunk with /splunk auth login to re-run the search")
+	}
+
+	results, err := s.runSavedSearch(user, searchName, rerunRows)
+	if err != nil {
+		return errors.Wrap(err, "error while running saved search")
+	}
+
+	username := userID
+	if u, userErr := s.GetUser(userID); userErr == nil {
+		username = u.Username
+	}
+	text := fmt.Sprintf("**Last refreshed** %s by @%s\n", time.Now().UTC().Format("Jan 2, 2006 15:04 MST"), username)
+	if table := results.MarkdownTable(); table != "" {
+		text += "\n" + table
+	} else {
+		text += "\nSearch has no results, the condition may have cleared."
+	}
+
+	model.ParseSlackAttachment(post, []*model.SlackAttachment{s.alertAttachment(alertID, searchName, text)})
+	if _, err = s.UpdatePost(post); err != nil {
+		return errors.Wrap(err, "error while updating alert post")
+	}
+	return nil
+}
+
+// alertPostSearch returns the alert ID and the saved search name of the re-run button of the alert post.
+func alertPostSearch(post *model.Post) (string, string) {
+	for _, attachment := range post.Attachments() {
+		for _, action := range attachment.Actions {
+			if action.Id != "rerun" || action.Integration == nil {
+				continue
+			}
+			alertID, _ := action.Integration.Context[rerunContextAlertID].(string)
+			searchName, _ := action.Integration.Context[rerunContextSearchName].(string)
+			return alertID, searchName
+		}
+	}
+	return "", ""
+}
+
+// channelUser returns splunk user used on behalf of the user in the channel,
+// the server bound to the channel is preferred over the last login.
+func (s *splunk) channelUser(userID string, channelID string) (store.SplunkUser, error) {
+	server, err := s.Store.GetChannelServer(channelID)
+	if err != nil {
+		return store.SplunkUser{}, err
+	}
+	if server != "" {
+		return s.Store.UserForServer(userID, server)
+	}
+
+	return s.Store.CurrentUser(userID)
+}
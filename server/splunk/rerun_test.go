@@ -0,0 +1,48 @@
+package splunk
+
+import (
+	"testing"
+
+	"github.com/mattermost/mattermost-server/v6/model"
+	"github.com/stretchr/testify/assert"
+)
+
+// postsAPI serves stored posts to users who can read their channels.
+type postsAPI struct {
+	fakePluginAPI
+	stored  map[string]*model.Post
+	readers map[string]bool
+}
+
+func (f *postsAPI) GetPost(postID string) (*model.Post, error) {
+	if post, ok := f.stored[postID]; ok {
+		return post, nil
+	}
+	return nil, errNotFound
+}
+
+func (f *postsAPI) HasPermissionToChannel(userID, channelID string, _ *model.Permission) bool {
+	return f.readers[userID+"/"+channelID]
+}
+
+func Test_splunk_RerunAlertSearchChecksPost(t *testing.T) {
+	api := &postsAPI{readers: map[string]bool{"member/channel": true}}
+	s := newSplunk(api, nil)
+	s.AddBotUser("bot")
+
+	alertPost := &model.Post{Id: "alert", UserId: "bot", ChannelId: "channel"}
+	model.ParseSlackAttachment(alertPost, []*model.SlackAttachment{s.alertAttachment("a1", "Errors", "")})
+	plainPost := &model.Post{Id: "plain", UserId: "bot", ChannelId: "channel"}
+	userPost := &model.Post{Id: "user", UserId: "user", ChannelId: "channel"}
+	model.ParseSlackAttachment(userPost, []*model.SlackAttachment{s.alertAttachment("a1", "Errors", "")})
+
+	api.stored = map[string]*model.Post{"alert": alertPost, "plain": plainPost, "user": userPost}
+
+	alertID, searchName := alertPostSearch(alertPost)
+	assert.Equal(t, "a1", alertID)
+	assert.Equal(t, "Errors", searchName)
+
+	assert.EqualError(t, s.RerunAlertSearch("member", "user"), "post is not an alert post")
+	assert.EqualError(t, s.RerunAlertSearch("outsider", "alert"), "you don't have access to the channel of the alert post")
+	assert.EqualError(t, s.RerunAlertSearch("member", "plain"), "alert post has no saved search")
+}
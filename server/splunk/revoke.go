@@ -0,0 +1,212 @@
+package splunk
+
+import (
+	"fmt"
+
+	"github.com/mattermost/mattermost-server/v6/model"
+	"github.com/pkg/errors"
+
+	"github.com/mattermost/mattermost-plugin-splunk/server/store"
+)
+
+// ErrAlertDisabled is returned when alert is delivered for a disabled subscription.
+var ErrAlertDisabled = errors.New("alert subscription is disabled")
+
+// RevokeDeactivatedUsers revokes credentials of deactivated and deleted users.
+// Mattermost has no plugin hook for deactivation, so users with stored credentials
+// and creators of alert subscriptions are checked periodically.
+func (s *splunk) RevokeDeactivatedUsers() {
+	ids, err := s.Store.GetUserIDs()
+	if err != nil {
+		s.LogError("failed to list users for credential revocation", "error", err.Error())
+		return
+	}
+	alerts, err := s.Store.GetAlerts()
+	if err != nil {
+		s.LogError("failed to load alert subscriptions for credential revocation", "error", err.Error())
+		return
+	}
+	// creators may have subscriptions without stored credentials, e.g. subscriptions using the service account
+	checked := make(map[string]bool, len(ids))
+	for _, id := range ids {
+		checked[id] = true
+	}
+	for _, alert := range alerts {
+		if alert.CreatorID != "" && alert.DisabledReason == "" && !checked[alert.CreatorID] {
+			checked[alert.CreatorID] = true
+			ids = append(ids, alert.CreatorID)
+		}
+	}
+
+	for _, id := range ids {
+		user, err := s.GetUser(id)
+		if err != nil {
+			// users are only revoked if they are confirmed to be gone, not when they can't be checked
+			if !isNotFound(err) {
+				s.LogWarn("failed to check user for credential revocation", "userID", id, "error", err.Error())
+				continue
+			}
+			s.RevokeUser(id, "was deleted")
+			continue
+		}
+		if user.DeleteAt != 0 {
+			s.RevokeUser(id, fmt.Sprintf("@%s was deactivated", user.Username))
+		}
+	}
+}
+
+// RevokeUser deletes stored splunk credentials of the user, disables alert subscriptions
+// and removes scheduled reports created by them, which can't be run without their credentials.
+// Channels of the subscriptions and reports are notified, reason describes what happened to the user
+// and follows "its creator" in the notifications.
+func (s *splunk) RevokeUser(mattermostUserID string, reason string) {
+	if err := s.Store.DeleteUserData(mattermostUserID); err != nil {
+		s.LogError("failed to delete user credentials", "userID", mattermostUserID, "error", err.Error())
+		return
+	}
+	s.LogInfo("splunk credentials revoked", "userID", mattermostUserID)
+	s.Audit(store.AuditEntry{
+		Action:  "credentials/revoke",
+		Details: fmt.Sprintf("user %s revoked, %s", mattermostUserID, reason),
+		Result:  "success",
+	})
+
+	s.disableCreatorAlerts(mattermostUserID, reason)
+
+	reports, err := s.Store.GetReportSchedules()
+	if err != nil {
+		s.LogError("failed to load scheduled reports", "error", err.Error())
+		return
+	}
+	for _, report := range reports {
+		if report.CreatorID != mattermostUserID {
+			continue
+		}
+		if err = s.Store.DeleteReportSchedule(report.ID); err != nil {
+			s.LogError("failed to remove scheduled report", "id", report.ID, "error", err.Error())
+			continue
+		}
+		s.notifyChannel(report.ChannelID, fmt.Sprintf("Scheduled report %q was removed because its creator %s and their Splunk credentials were revoked.", report.Report, reason))
+	}
+}
+
+// UserLeftChannel notifies the channel about alert subscriptions the user created in it.
+// The subscriptions keep posting alerts, they are only disabled when the credentials of the user are revoked.
+func (s *splunk) UserLeftChannel(mattermostUserID string, channelID string) {
+	s.reportCreatorLeft(mattermostUserID, s.userReason(mattermostUserID, "left the channel"), func(alert store.Alert) bool {
+		return alert.ChannelID == channelID
+	})
+}
+
+// UserLeftTeam notifies channels of the team about alert subscriptions the user created in them.
+func (s *splunk) UserLeftTeam(mattermostUserID string, teamID string) {
+	s.reportCreatorLeft(mattermostUserID, s.userReason(mattermostUserID, "left the team"), func(alert store.Alert) bool {
+		channel, err := s.GetChannel(alert.ChannelID)
+		return err == nil && channel.TeamId == teamID
+	})
+}
+
+// reportCreatorLeft notifies channels of enabled subscriptions created by the user for which inScope returns true.
+func (s *splunk) reportCreatorLeft(mattermostUserID string, reason string, inScope func(store.Alert) bool) {
+	alerts, err := s.Store.GetAlerts()
+	if err != nil {
+		s.LogError("failed to load alert subscriptions", "error", err.Error())
+		return
+	}
+
+	for _, alert := range alerts {
+		if alert.CreatorID != mattermostUserID || alert.DisabledReason != "" || !inScope(alert) {
+			continue
+		}
+		s.LogInfo("creator of alert subscription left", "alertID", alert.ID, "channelID", alert.ChannelID, "reason", reason)
+		label := alertLabel(alert)
+		s.notifyChannel(alert.ChannelID, fmt.Sprintf(
+			"The creator of alert subscription %s %s. Alerts are still posted and their Splunk credentials are still used for it, "+
+				"delete it with **/splunk alert delete %s** if it's no longer needed.",
+			label, reason, label))
+	}
+}
+
+// EnableAlert enables disabled alert subscription of the channel given by its name or ID.
+// The user becomes its creator and their splunk user is used to fetch alert results.
+func (s *splunk) EnableAlert(channelID string, ref string, mattermostUserID string) error {
+	alert, err := s.findAlert(channelID, ref)
+	if err != nil {
+		return err
+	}
+	if alert.DisabledReason == "" {
+		return errors.New("alert is not disabled")
+	}
+
+	user, _ := s.channelUser(mattermostUserID, channelID)
+	alert.CreatorID, alert.Server, alert.UserName = mattermostUserID, user.Server, user.UserName
+	alert.DisabledReason = ""
+	if err = s.Store.UpdateAlert(alert); err != nil {
+		return errors.Wrap(err, "error in storing alert")
+	}
+	return nil
+}
+
+// disableCreatorAlerts disables enabled subscriptions created by the user and notifies their channels.
+func (s *splunk) disableCreatorAlerts(mattermostUserID string, reason string) {
+	alerts, err := s.Store.GetAlerts()
+	if err != nil {
+		s.LogError("failed to load alert subscriptions", "error", err.Error())
+		return
+	}
+
+	for _, alert := range alerts {
+		if alert.CreatorID != mattermostUserID || alert.DisabledReason != "" {
+			continue
+		}
+		// the subscription is loaded again so that changes made since listing are kept
+		current, loadErr := s.Store.GetAlert(alert.ID)
+		if loadErr != nil {
+			s.LogError("failed to load alert subscription", "alertID", alert.ID, "error", loadErr.Error())
+			continue
+		}
+		if current.ChannelID == "" || current.CreatorID != mattermostUserID || current.DisabledReason != "" {
+			continue
+		}
+		alert = current
+
+		alert.DisabledReason = "its creator " + reason
+		if err = s.Store.UpdateAlert(alert); err != nil {
+			s.LogError("failed to disable alert subscription", "alertID", alert.ID, "error", err.Error())
+			continue
+		}
+		s.LogInfo("alert subscription disabled", "alertID", alert.ID, "channelID", alert.ChannelID, "reason", alert.DisabledReason)
+		s.Audit(store.AuditEntry{
+			Action:    "alert/disable",
+			ChannelID: alert.ChannelID,
+			Details:   fmt.Sprintf("%s disabled because %s", alert.ID, alert.DisabledReason),
+			Result:    "success",
+		})
+
+		label := alertLabel(alert)
+		s.notifyChannel(alert.ChannelID, fmt.Sprintf(
+			"Alert subscription %s was disabled because %s, so their Splunk credentials can no longer be used for it. "+
+				"Alerts sent to it are rejected until a system admin enables it with **/splunk alert enable %s**.",
+			label, alert.DisabledReason, label))
+	}
+}
+
+// userReason describes what the user did with their username, falling back to a generic description.
+func (s *splunk) userReason(mattermostUserID string, action string) string {
+	user, err := s.GetUser(mattermostUserID)
+	if err != nil {
+		return action
+	}
+	return fmt.Sprintf("@%s %s", user.Username, action)
+}
+
+// notifyChannel posts bot message to the channel, failures are only logged.
+func (s *splunk) notifyChannel(channelID string, message string) {
+	if _, err := s.CreatePost(&model.Post{
+		UserId:    s.BotUser(),
+		ChannelId: channelID,
+		Message:   message,
+	}); err != nil {
+		s.LogWarn("failed to notify channel", "channelID", channelID, "error", err.Error())
+	}
+}
@@ -0,0 +1,104 @@
+package splunk
+
+import (
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/mattermost/mattermost-server/v6/model"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/mattermost/mattermost-plugin-splunk/server/store"
+	"github.com/mattermost/mattermost-plugin-splunk/server/store/mock"
+)
+
+func Test_splunk_RevokeDeactivatedUsers(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	is := assert.New(t)
+
+	api := &fakeDirectoryAPI{
+		users: map[string]*model.User{
+			"john":    {Id: "john", Username: "john"},
+			"retired": {Id: "retired", Username: "retired", DeleteAt: 1},
+		},
+		failing: map[string]bool{"unknown": true},
+	}
+
+	m := mock.NewMockStore(ctrl)
+	// "unknown" can't be checked, so its credentials are kept
+	// "gone" has no stored credentials but is the creator of a subscription
+	m.EXPECT().GetUserIDs().Return([]string{"john", "retired", "unknown"}, nil)
+	m.EXPECT().DeleteUserData("retired").Return(nil)
+	m.EXPECT().DeleteUserData("gone").Return(nil)
+	m.EXPECT().GetAlerts().Return([]store.Alert{
+		{ID: "a1", ChannelID: "channel", CreatorID: "retired", Name: "cpu"},
+		{ID: "a2", ChannelID: "channel", CreatorID: "john"},
+		{ID: "a3", ChannelID: "channel", CreatorID: "retired", DisabledReason: "its creator left the channel"},
+		{ID: "a4", ChannelID: "channel", CreatorID: "gone"},
+	}, nil).AnyTimes()
+	// a1 was muted since listing
+	m.EXPECT().GetAlert("a1").Return(store.Alert{ID: "a1", ChannelID: "channel", CreatorID: "retired", Name: "cpu", MutedUntil: 5}, nil)
+	m.EXPECT().GetAlert("a4").Return(store.Alert{ID: "a4", ChannelID: "channel", CreatorID: "gone"}, nil)
+	m.EXPECT().UpdateAlert(store.Alert{ID: "a1", ChannelID: "channel", CreatorID: "retired", Name: "cpu", MutedUntil: 5, DisabledReason: "its creator @retired was deactivated"}).Return(nil)
+	m.EXPECT().UpdateAlert(store.Alert{ID: "a4", ChannelID: "channel", CreatorID: "gone", DisabledReason: "its creator was deleted"}).Return(nil)
+	m.EXPECT().GetReportSchedules().Return([]store.ReportSchedule{
+		{ID: "r1", ChannelID: "reports", CreatorID: "retired", Report: "Errors"},
+		{ID: "r2", ChannelID: "reports", CreatorID: "john", Report: "Errors"},
+	}, nil).Times(2)
+	m.EXPECT().DeleteReportSchedule("r1").Return(nil)
+	var actions []string
+	m.EXPECT().AppendAuditEntry(gomock.Any()).DoAndReturn(func(entry store.AuditEntry) error {
+		actions = append(actions, entry.Action)
+		return nil
+	}).Times(4)
+
+	s := newSplunk(api, m)
+	s.RevokeDeactivatedUsers()
+
+	is.Equal([]string{"credentials/revoke", "alert/disable", "credentials/revoke", "alert/disable"}, actions)
+	is.Len(api.posts, 3)
+	is.Equal("channel", api.posts[0].ChannelId)
+	is.Contains(api.posts[0].Message, "/splunk alert enable cpu")
+	is.Equal("reports", api.posts[1].ChannelId)
+	is.Equal("channel", api.posts[2].ChannelId)
+}
+
+func Test_splunk_UserLeftTeam(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	api := &fakeDirectoryAPI{
+		channels: map[string]*model.Channel{
+			"team/town-square":  {Id: "channel", TeamId: "team"},
+			"other/town-square": {Id: "other-channel", TeamId: "other"},
+		},
+		users: map[string]*model.User{"john": {Id: "john", Username: "john"}},
+	}
+
+	m := mock.NewMockStore(ctrl)
+	m.EXPECT().GetAlerts().Return([]store.Alert{
+		{ID: "a1", ChannelID: "channel", CreatorID: "john"},
+		{ID: "a2", ChannelID: "other-channel", CreatorID: "john"},
+	}, nil)
+
+	s := newSplunk(api, m)
+	s.UserLeftTeam("john", "team")
+	assert.Len(t, api.posts, 1, "subscription is reported, not disabled")
+	assert.Equal(t, "channel", api.posts[0].ChannelId)
+	assert.Contains(t, api.posts[0].Message, "@john left the team")
+}
+
+func Test_splunk_EnableAlert(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	m := mock.NewMockStore(ctrl)
+	m.EXPECT().GetChannelAlertIDs("channel").Return([]string{"a1"}, nil).AnyTimes()
+	m.EXPECT().GetAlert("a1").Return(store.Alert{ID: "a1", ChannelID: "channel", CreatorID: "retired", DisabledReason: "its creator was deleted"}, nil).AnyTimes()
+	m.EXPECT().GetChannelServer("channel").Return("", nil).AnyTimes()
+	m.EXPECT().CurrentUser("admin").Return(store.SplunkUser{Server: "https://splunk:8089", UserName: "admin"}, nil).AnyTimes()
+	m.EXPECT().UpdateAlert(store.Alert{ID: "a1", ChannelID: "channel", CreatorID: "admin", Server: "https://splunk:8089", UserName: "admin"}).Return(nil)
+
+	s := newSplunk(&fakePluginAPI{}, m)
+	assert.NoError(t, s.EnableAlert("channel", "a1", "admin"))
+}
@@ -0,0 +1,72 @@
+package splunk
+
+import (
+	"strings"
+
+	"github.com/mattermost/mattermost-plugin-splunk/server/config"
+	"github.com/mattermost/mattermost-plugin-splunk/server/store"
+)
+
+// alertChannels returns IDs of the channels the alert with the given severity is posted to.
+// Team routes matching the team of the subscription channel take precedence over global ones,
+// alerts which don't match any route are posted to the subscription channel.
+func (s *splunk) alertChannels(alert store.Alert, severity string) []string {
+	defaultChannels := []string{alert.ChannelID}
+	if severity == "" {
+		return defaultChannels
+	}
+
+	routes, err := config.ParseSeverityRouting(s.GetConfiguration().SeverityRouting)
+	if err != nil {
+		s.LogWarn("invalid severity routing", "error", err.Error())
+		return defaultChannels
+	}
+	if len(routes) == 0 {
+		return defaultChannels
+	}
+
+	teamName := ""
+	if channel, channelErr := s.GetChannel(alert.ChannelID); channelErr == nil && channel.TeamId != "" {
+		if team, teamErr := s.GetTeam(channel.TeamId); teamErr == nil {
+			teamName = team.Name
+		}
+	}
+
+	var global, team []config.SeverityRoute
+	for _, route := range routes {
+		switch {
+		case route.Severity != severity:
+		case route.Team == "":
+			global = append(global, route)
+		case route.Team == teamName:
+			team = append(team, route)
+		}
+	}
+	matched := team
+	if len(matched) == 0 {
+		matched = global
+	}
+
+	var channelIDs []string
+	found := make(map[string]bool)
+	for _, route := range matched {
+		channelTeam, channelName := teamName, route.Channel
+		if idx := strings.Index(route.Channel, "/"); idx != -1 {
+			channelTeam, channelName = route.Channel[:idx], route.Channel[idx+1:]
+		}
+		channel, channelErr := s.GetChannelByNameForTeamName(channelTeam, channelName)
+		if channelErr != nil {
+			s.LogWarn("failed to find severity routing channel", "channel", route.Channel, "team", channelTeam, "error", channelErr.Error())
+			continue
+		}
+		if !found[channel.Id] {
+			found[channel.Id] = true
+			channelIDs = append(channelIDs, channel.Id)
+		}
+	}
+
+	if len(channelIDs) == 0 {
+		return defaultChannels
+	}
+	return channelIDs
+}
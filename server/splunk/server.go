@@ -0,0 +1,103 @@
+package splunk
+
+import (
+	"crypto/x509"
+	"net"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"github.com/pkg/errors"
+
+	"github.com/mattermost/mattermost-plugin-splunk/server/store"
+)
+
+const (
+	// cloudHostSuffix is the domain of Splunk Cloud deployments
+	cloudHostSuffix = ".splunkcloud.com"
+	// cloudHECHostPrefix is the prefix of Splunk Cloud HTTP Event Collector hosts
+	cloudHECHostPrefix = "http-inputs-"
+	// cloudManagementPort is the only port Splunk Cloud serves REST API on
+	cloudManagementPort = "8089"
+
+	bearerAuthScheme = "Bearer"
+	splunkAuthScheme = "Splunk"
+)
+
+// ServerURL returns base URL of the splunk REST API and the server type for the given server base URL.
+// Type is detected from the host if serverType is empty.
+// Splunk Cloud REST API is only served over https on the management port, so other ports are replaced.
+// Splunk Enterprise URLs without a port get managementPort unless it's zero, their path is kept.
+func ServerURL(server string, serverType string, managementPort int) (string, string, error) {
+	u, err := url.Parse(server)
+	if err != nil {
+		return "", "", errors.Wrap(err, "bad url")
+	}
+	if serverType == "" {
+		serverType = store.ServerTypeEnterprise
+		if strings.HasSuffix(u.Hostname(), cloudHostSuffix) {
+			serverType = store.ServerTypeCloud
+		}
+	}
+
+	switch serverType {
+	case store.ServerTypeEnterprise:
+		// the path is kept for servers behind a reverse proxy with a path prefix
+		host, path := u.Host, strings.TrimSuffix(u.Path, "/")
+		if u.Port() == "" && managementPort != 0 {
+			host = net.JoinHostPort(u.Hostname(), strconv.Itoa(managementPort))
+		}
+		return u.Scheme + "://" + host + path, serverType, nil
+	case store.ServerTypeCloud:
+		// HEC host is usually at hand when setting up Splunk Cloud but REST API is on the stack host
+		host := strings.TrimPrefix(u.Hostname(), cloudHECHostPrefix)
+		return "https://" + net.JoinHostPort(host, cloudManagementPort), serverType, nil
+	default:
+		return "", "", errors.Errorf("unknown server type %q", serverType)
+	}
+}
+
+// authorizationHeader returns Authorization header value for the token of the user.
+// Users stored without a scheme keep the Bearer scheme which was used for all tokens before.
+func authorizationHeader(user store.SplunkUser) string {
+	scheme := user.AuthScheme
+	if scheme == "" {
+		scheme = bearerAuthScheme
+	}
+	return scheme + " " + user.Token
+}
+
+// tokenAuthScheme returns the Authorization header scheme for the token.
+// Splunk authentication tokens are JWTs and use Bearer scheme, session keys use Splunk scheme.
+func tokenAuthScheme(token string) string {
+	if strings.Count(token, ".") == 2 {
+		return bearerAuthScheme
+	}
+	return splunkAuthScheme
+}
+
+// loginError converts error of the login check into a message explaining how to fix it.
+func loginError(user store.SplunkUser, err error) error {
+	cloud := user.Type == store.ServerTypeCloud
+
+	var urlErr *url.Error
+	var certErr x509.UnknownAuthorityError
+	var hostErr x509.HostnameError
+	switch {
+	case isStatusCode(err, http.StatusUnauthorized):
+		return errors.New("the token was rejected. Check that it's not expired and that token authentication is enabled under Settings > Tokens")
+	case isStatusCode(err, http.StatusForbidden):
+		return errors.New("the user is not allowed to use the REST API. Check the roles of the Splunk user")
+	case errors.As(err, &certErr), errors.As(err, &hostErr):
+		return errors.Errorf("the TLS certificate of %s is not trusted by the Mattermost server", user.Server)
+	case errors.As(err, &urlErr) && cloud:
+		return errors.Errorf("could not reach %s. Splunk Cloud only allows REST API access from allowed IP addresses, ask your Splunk Cloud admin to add the Mattermost server address to the search head API access list", user.Server)
+	case errors.As(err, &urlErr):
+		return errors.Errorf("could not reach %s. Check the server URL and that the management port (8089 by default) is reachable from the Mattermost server", user.Server)
+	case cloud:
+		return errors.Wrap(err, "unexpected response, check the Splunk Cloud stack URL")
+	default:
+		return errors.Wrapf(err, "unexpected response, check that %s is the management port of Splunk and not the web interface", user.Server)
+	}
+}
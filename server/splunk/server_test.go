@@ -0,0 +1,68 @@
+package splunk
+
+import (
+	"net/http"
+	"net/url"
+	"testing"
+
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/mattermost/mattermost-plugin-splunk/server/store"
+)
+
+func TestServerURL(t *testing.T) {
+	tests := []struct {
+		name       string
+		server     string
+		serverType string
+		port       int
+		want       string
+		wantType   string
+		wantErr    bool
+	}{
+		{name: "enterprise", server: "http://splunk.example.com:8089", want: "http://splunk.example.com:8089", wantType: store.ServerTypeEnterprise},
+		{name: "enterprise default port", server: "https://splunk.example.com", port: 8089, want: "https://splunk.example.com:8089", wantType: store.ServerTypeEnterprise},
+		{name: "enterprise path prefix", server: "https://proxy.example.com/splunk/", port: 8089, want: "https://proxy.example.com:8089/splunk", wantType: store.ServerTypeEnterprise},
+		{name: "enterprise path prefix without port", server: "https://proxy.example.com/splunk", want: "https://proxy.example.com/splunk", wantType: store.ServerTypeEnterprise},
+		{name: "enterprise explicit port", server: "https://splunk.example.com:443", port: 8089, want: "https://splunk.example.com:443", wantType: store.ServerTypeEnterprise},
+		{name: "cloud detected", server: "https://acme.splunkcloud.com", want: "https://acme.splunkcloud.com:8089", wantType: store.ServerTypeCloud},
+		{name: "cloud web port", server: "http://acme.splunkcloud.com:8000", want: "https://acme.splunkcloud.com:8089", wantType: store.ServerTypeCloud},
+		{name: "cloud HEC host", server: "https://http-inputs-acme.splunkcloud.com", want: "https://acme.splunkcloud.com:8089", wantType: store.ServerTypeCloud},
+		{name: "cloud custom domain", server: "https://splunk.example.com", serverType: store.ServerTypeCloud, want: "https://splunk.example.com:8089", wantType: store.ServerTypeCloud},
+		{name: "forced enterprise", server: "https://acme.splunkcloud.com:443", serverType: store.ServerTypeEnterprise, want: "https://acme.splunkcloud.com:443", wantType: store.ServerTypeEnterprise},
+		{name: "unknown type", server: "https://splunk.example.com", serverType: "other", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, gotType, err := ServerURL(tt.server, tt.serverType, tt.port)
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+			assert.Equal(t, tt.wantType, gotType)
+		})
+	}
+}
+
+func Test_authorizationHeader(t *testing.T) {
+	jwt, sessionKey := "eyJra.eyJpc.sig", "3f2a9c^sessionkey"
+	assert.Equal(t, "Bearer eyJra.eyJpc.sig", authorizationHeader(store.SplunkUser{Token: jwt, AuthScheme: tokenAuthScheme(jwt)}))
+	assert.Equal(t, "Splunk 3f2a9c^sessionkey", authorizationHeader(store.SplunkUser{Token: sessionKey, AuthScheme: tokenAuthScheme(sessionKey)}))
+	assert.Equal(t, "Bearer 3f2a9c^sessionkey", authorizationHeader(store.SplunkUser{Token: sessionKey}), "stored users keep the scheme")
+}
+
+func Test_loginError(t *testing.T) {
+	enterprise := store.SplunkUser{Server: "https://splunk.example.com:8089", Type: store.ServerTypeEnterprise}
+	cloud := store.SplunkUser{Server: "https://acme.splunkcloud.com:8089", Type: store.ServerTypeCloud}
+	unreachable := errors.Wrap(&url.Error{Op: "Get", URL: cloud.Server, Err: errors.New("i/o timeout")}, "connection problem")
+
+	assert.Contains(t, loginError(enterprise, errors.Wrap(statusCodeError(http.StatusUnauthorized), "authorization")).Error(), "token was rejected")
+	assert.Contains(t, loginError(enterprise, statusCodeError(http.StatusForbidden)).Error(), "not allowed to use the REST API")
+	assert.Contains(t, loginError(cloud, unreachable).Error(), "search head API access list")
+	assert.Contains(t, loginError(enterprise, unreachable).Error(), "management port (8089 by default)")
+	assert.Contains(t, loginError(enterprise, errors.New("EOF")).Error(), "not the web interface")
+}
@@ -0,0 +1,271 @@
+package splunk
+
+import (
+	"fmt"
+	"net/url"
+	"strings"
+
+	"github.com/mattermost/mattermost-server/v6/model"
+	"github.com/pkg/errors"
+
+	"github.com/mattermost/mattermost-plugin-splunk/server/config"
+	"github.com/mattermost/mattermost-plugin-splunk/server/store"
+)
+
+const (
+	// SetupConnectEndpoint is called when the connect dialog of the setup is submitted
+	SetupConnectEndpoint = "/setup/connect"
+	// SetupSubscriptionEndpoint is called by the button opening the test subscription dialog
+	SetupSubscriptionEndpoint = "/setup/subscription"
+	// SetupSubscribeEndpoint is called when the test subscription dialog is submitted
+	SetupSubscribeEndpoint = "/setup/subscribe"
+
+	serverTypeAuto = "auto"
+
+	onboardingPerPage = 100
+
+	onboardingMessage = `#### Welcome to the Splunk plugin!
+Here is how to get started:
+1. Run **/splunk setup** to connect to your Splunk server and create a first test alert subscription.
+2. In Splunk, add a webhook alert action to any alert with the webhook URL of the subscription. Its alerts will be posted to the subscribed channel.
+3. Review the webhook secret, rate limits and other settings in **System Console > Plugins > Splunk**.
+
+Run **/splunk help** to see all commands.`
+)
+
+// OnboardSysAdmins sends getting started message to system admins who haven't received it yet.
+func (s *splunk) OnboardSysAdmins() {
+	for page := 0; ; page++ {
+		admins, err := s.GetUsers(&model.UserGetOptions{
+			Role:    model.SystemAdminRoleId,
+			Active:  true,
+			Page:    page,
+			PerPage: onboardingPerPage,
+		})
+		if err != nil {
+			s.LogError("failed to get system admins for onboarding", "error", err.Error())
+			return
+		}
+
+		for _, admin := range admins {
+			if admin.IsBot {
+				continue
+			}
+			if err = s.onboardUser(admin.Id); err != nil {
+				s.LogWarn("failed to send getting started message", "userID", admin.Id, "error", err.Error())
+			}
+		}
+
+		if len(admins) < onboardingPerPage {
+			return
+		}
+	}
+}
+
+func (s *splunk) onboardUser(userID string) error {
+	onboarded, err := s.Store.IsOnboarded(userID)
+	if err != nil || onboarded {
+		return err
+	}
+
+	channel, err := s.GetDirectChannel(userID, s.BotUser())
+	if err != nil {
+		return err
+	}
+	if _, err = s.CreatePost(&model.Post{
+		UserId:    s.BotUser(),
+		ChannelId: channel.Id,
+		Message:   onboardingMessage,
+	}); err != nil {
+		return err
+	}
+
+	return s.Store.SetOnboarded(userID)
+}
+
+// StartSetup opens the first dialog of the setup, which connects the user to splunk server.
+func (s *splunk) StartSetup(userID string, triggerID string) error {
+	current, _ := s.Store.CurrentUser(userID)
+	if current.Server == "" {
+		current.Server = s.GetConfiguration().DefaultServer
+	}
+
+	return s.OpenInteractiveDialog(model.OpenDialogRequest{
+		TriggerId: triggerID,
+		URL:       s.pluginURL(SetupConnectEndpoint),
+		Dialog: model.Dialog{
+			CallbackId:       "setup_connect",
+			Title:            "Connect to Splunk",
+			IntroductionText: "Enter the URL of your Splunk server and an authentication token created under **Settings > Tokens** in Splunk.",
+			SubmitLabel:      "Connect",
+			Elements: []model.DialogElement{
+				{
+					DisplayName: "Server URL",
+					Name:        "server",
+					Type:        "text",
+					SubType:     "url",
+					Default:     current.Server,
+					Placeholder: "https://splunk.example.com:8089",
+					HelpText:    "Base URL of the Splunk REST API, usually on the management port 8089",
+				},
+				{
+					DisplayName: "Server type",
+					Name:        "server_type",
+					Type:        "select",
+					Default:     serverTypeAuto,
+					Options: []*model.PostActionOptions{
+						{Text: "Detect from URL", Value: serverTypeAuto},
+						{Text: "Splunk Enterprise", Value: store.ServerTypeEnterprise},
+						{Text: "Splunk Cloud", Value: store.ServerTypeCloud},
+					},
+				},
+				{
+					DisplayName: "Username",
+					Name:        "username",
+					Type:        "text",
+					Default:     current.UserName,
+				},
+				{
+					DisplayName: "Token",
+					Name:        "token",
+					Type:        "text",
+					SubType:     "password",
+					Optional:    true,
+					HelpText:    "Can be left empty if you already logged in as this user",
+				},
+			},
+		},
+	})
+}
+
+// SetupConnect logs the user in with the submitted connect dialog
+// and lets them continue with the test subscription.
+// Returned map has errors of the dialog fields.
+func (s *splunk) SetupConnect(userID string, channelID string, submission map[string]interface{}) (map[string]string, error) {
+	server, _ := submission["server"].(string)
+	serverType, _ := submission["server_type"].(string)
+	username, _ := submission["username"].(string)
+	token, _ := submission["token"].(string)
+
+	server = strings.TrimSpace(server)
+	if !strings.Contains(server, "://") {
+		server = "https://" + server
+	}
+	if u, err := url.Parse(server); err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return map[string]string{"server": "Enter the server URL, e.g. https://splunk.example.com:8089"}, nil
+	}
+	if serverType == serverTypeAuto {
+		serverType = ""
+	}
+
+	id := strings.TrimSpace(username)
+	if token = strings.TrimSpace(token); token != "" {
+		id += "/" + token
+	}
+	err := s.LoginUser(userID, server, serverType, id)
+	s.Audit(store.AuditEntry{
+		ActorID:   userID,
+		Action:    "setup/connect",
+		ChannelID: channelID,
+		Details:   server + " " + strings.TrimSpace(username),
+		Result:    AuditResult(err),
+	})
+	if err != nil {
+		return map[string]string{"token": err.Error()}, nil
+	}
+
+	post := &model.Post{
+		UserId:    s.BotUser(),
+		ChannelId: channelID,
+		Message:   fmt.Sprintf("Connected to %s as %s.", s.User().Server, s.User().UserName),
+	}
+	if s.HasPermissionTo(userID, model.PermissionManageSystem) {
+		model.ParseSlackAttachment(post, []*model.SlackAttachment{{
+			Text: "Next, create a test alert subscription to see Splunk alerts in a channel.",
+			Actions: []*model.PostAction{{
+				Id:   "setupsubscription",
+				Name: "Create test subscription",
+				Type: model.PostActionTypeButton,
+				Integration: &model.PostActionIntegration{
+					URL: s.pluginURL(SetupSubscriptionEndpoint),
+				},
+			}},
+		}})
+	} else {
+		post.Message += " Ask a system admin to subscribe a channel to Splunk alerts with **/splunk setup** or **/splunk alert subscribe**."
+	}
+	s.SendEphemeralPost(userID, post)
+	return nil, nil
+}
+
+// OpenSetupSubscription opens the test subscription dialog of the setup.
+func (s *splunk) OpenSetupSubscription(userID string, triggerID string, channelID string) error {
+	if !s.HasPermissionTo(userID, model.PermissionManageSystem) {
+		return errors.New("you need to be a sysadmin to subscribe to alerts")
+	}
+
+	return s.OpenInteractiveDialog(model.OpenDialogRequest{
+		TriggerId: triggerID,
+		URL:       s.pluginURL(SetupSubscribeEndpoint),
+		Dialog: model.Dialog{
+			CallbackId:       "setup_subscribe",
+			Title:            "Create test subscription",
+			IntroductionText: "The channel will get the webhook URL of the subscription and a test alert.",
+			SubmitLabel:      "Subscribe",
+			Elements: []model.DialogElement{
+				{
+					DisplayName: "Channel",
+					Name:        "channel_id",
+					Type:        "select",
+					DataSource:  "channels",
+					Default:     channelID,
+				},
+			},
+		},
+	})
+}
+
+// SetupSubscribe subscribes the channel to the alert, posts its webhook URL and a test alert to the channel.
+func (s *splunk) SetupSubscribe(userID string, channelID string, alertID string, webhookURL string) error {
+	if !s.HasPermissionTo(userID, model.PermissionManageSystem) {
+		return errors.New("you need to be a sysadmin to subscribe to alerts")
+	}
+
+	user, _ := s.channelUser(userID, channelID)
+	err := s.AddAlert(store.Alert{
+		ID:        alertID,
+		ChannelID: channelID,
+		CreatorID: userID,
+		Server:    user.Server,
+		UserName:  user.UserName,
+	})
+	s.Audit(store.AuditEntry{
+		ActorID:   userID,
+		Action:    "setup/subscribe",
+		ChannelID: channelID,
+		Details:   alertID,
+		Result:    AuditResult(err),
+	})
+	if err != nil {
+		return err
+	}
+
+	for _, message := range []string{
+		fmt.Sprintf("Added alert\nCopy this [webhook url](%s) to your splunk alert action.", webhookURL),
+		fmt.Sprintf("This is a test alert of the subscription %s. Alerts triggered in Splunk will be posted here.", alertID),
+	} {
+		if _, err = s.CreatePost(&model.Post{
+			UserId:    s.BotUser(),
+			ChannelId: channelID,
+			Message:   message,
+		}); err != nil {
+			return errors.Wrap(err, "error creating post for test subscription")
+		}
+	}
+	return nil
+}
+
+// pluginURL returns plugin relative URL of the api endpoint used by post actions and dialogs.
+func (s *splunk) pluginURL(endpoint string) string {
+	return fmt.Sprintf("/plugins/%s%s%s", s.GetConfiguration().PluginID, config.APIPath, endpoint)
+}
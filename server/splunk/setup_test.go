@@ -0,0 +1,58 @@
+package splunk
+
+import (
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/mattermost/mattermost-server/v6/model"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/mattermost/mattermost-plugin-splunk/server/store/mock"
+)
+
+type fakeSetupAPI struct {
+	fakePluginAPI
+	admins []*model.User
+}
+
+func (f *fakeSetupAPI) GetUsers(options *model.UserGetOptions) ([]*model.User, error) {
+	if options.Page > 0 {
+		return nil, nil
+	}
+	return f.admins, nil
+}
+
+func (f *fakeSetupAPI) GetDirectChannel(userID1, userID2 string) (*model.Channel, error) {
+	return &model.Channel{Id: userID1 + "__" + userID2}, nil
+}
+
+func Test_splunk_OnboardSysAdmins(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	is := assert.New(t)
+
+	m := mock.NewMockStore(ctrl)
+	m.EXPECT().IsOnboarded("admin1").Return(false, nil)
+	m.EXPECT().IsOnboarded("admin2").Return(true, nil)
+	m.EXPECT().SetOnboarded("admin1").Return(nil)
+
+	api := &fakeSetupAPI{admins: []*model.User{{Id: "admin1"}, {Id: "admin2"}, {Id: "bot", IsBot: true}}}
+	s := newSplunk(api, m)
+	s.AddBotUser("splunkbot")
+	s.OnboardSysAdmins()
+
+	is.Len(api.posts, 1)
+	is.Equal("admin1__splunkbot", api.posts[0].ChannelId)
+	is.Equal("splunkbot", api.posts[0].UserId)
+}
+
+func Test_splunk_SetupConnectValidatesServer(t *testing.T) {
+	s := newSplunk(&fakeSetupAPI{}, nil)
+
+	fieldErrors, err := s.SetupConnect("user", "channel", map[string]interface{}{
+		"server":   "ftp://splunk.example.com",
+		"username": "admin",
+	})
+	assert.NoError(t, err)
+	assert.Contains(t, fieldErrors, "server")
+}
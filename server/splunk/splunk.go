@@ -1,11 +1,15 @@
 package splunk
 
 import (
+	"context"
 	"encoding/xml"
 	"log"
 	"net/http"
 	"strings"
+	"time"
 
+	"github.com/mattermost/mattermost-plugin-splunk/server/config"
+	"github.com/mattermost/mattermost-plugin-splunk/server/jobs"
 	"github.com/mattermost/mattermost-plugin-splunk/server/store"
 
 	"github.com/mattermost/mattermost-server/v6/model"
@@ -18,19 +22,63 @@ type Splunk interface {
 
 	User() store.SplunkUser
 	SyncUser(mattermostUserID string) error
-	LoginUser(mattermostUserID string, server string, id string) error
+	SyncUserForServer(mattermostUserID string, server string) error
+	LoginUser(mattermostUserID string, server string, serverType string, id string) error
 	LogoutUser(mattermostUserID string) error
 
-	AddAlert(string, string) error
+	AddAlert(store.Alert) error
+	DecodeAlertPayload(string, []byte) (AlertActionWHPayload, error)
 	Notify(string, AlertActionWHPayload) error
-	ListAlert(string) ([]string, error)
+	AllowAlert(string, int, int) (time.Duration, error)
+	ListAlert(string) ([]store.Alert, error)
 	DeleteAlert(string, string) error
+	RenameAlert(string, string, string) error
+	SetAlertTemplate(string, string, string) error
+	SetAlertPayloadFormat(string, string, string) error
+	EnableAlert(string, string, string) error
+	MuteAlert(string, string, string, time.Duration) (time.Time, error)
+	UnmuteAlert(string, string, string) error
+	SnoozeAlert(string, map[string]interface{}) (time.Time, error)
+	ResumeMutedAlerts()
+	RerunAlertSearch(string, string) error
+	CleanupSubscriptions()
+	ReportDeliveryError(DeliveryError)
+	Audit(store.AuditEntry)
+	AuditLog(int) ([]store.AuditEntry, error)
+	RevokeDeactivatedUsers()
+	RevokeUser(string, string)
+	UserLeftChannel(string, string)
+	UserLeftTeam(string, string)
+
+	BindChannel(string, string) error
+	UnbindChannel(string) error
+	ChannelBinding(string) (string, error)
 
 	AddBotUser(string)
 	BotUser() string
 
-	Logs(string) (LogResults, error)
-	ListLogs() []string
+	ScheduleReport(store.ReportSchedule) (store.ReportSchedule, error)
+	ListReports(string) ([]store.ReportSchedule, error)
+	DeleteReport(string, string) (store.ReportSchedule, error)
+	RunDueReports()
+
+	OnboardSysAdmins()
+	MigrateServers()
+	Close()
+	Jobs() []jobs.Job
+	StartSetup(string, string) error
+	SetupConnect(string, string, map[string]interface{}) (map[string]string, error)
+	OpenSetupSubscription(string, string, string) error
+	SetupSubscribe(string, string, string, string) error
+
+	ExportData(bool) (store.Bundle, error)
+	ImportData(store.Bundle) (store.ImportSummary, error)
+
+	AddLogSource(string, string, string, string) (store.LogSource, error)
+	ListLogSources(string) ([]store.LogSource, error)
+	RemoveLogSource(string, string) error
+	Logs(string, string) (LogResults, error)
+	ExportLogs(string, string, string) error
 }
 
 // check if the interface implements all methods
@@ -40,9 +88,25 @@ var _ Splunk = (*splunk)(nil)
 type PluginAPI interface {
 	SendEphemeralPost(userID string, post *model.Post) *model.Post
 	CreatePost(post *model.Post) (*model.Post, error)
+	GetPost(postID string) (*model.Post, error)
+	UpdatePost(post *model.Post) (*model.Post, error)
+	UploadFile(data []byte, channelID string, filename string) (*model.FileInfo, error)
 
+	OpenInteractiveDialog(dialog model.OpenDialogRequest) error
+
+	GetUsers(options *model.UserGetOptions) ([]*model.User, error)
 	GetUsersInChannel(channelID, sortBy string, page, perPage int) ([]*model.User, error)
+	GetUser(userID string) (*model.User, error)
+	GetUserByUsername(username string) (*model.User, error)
+	GetChannel(channelID string) (*model.Channel, error)
+	GetDirectChannel(userID1, userID2 string) (*model.Channel, error)
+	GetChannelByNameForTeamName(teamName, channelName string) (*model.Channel, error)
+	GetTeam(teamID string) (*model.Team, error)
+	HasPermissionTo(userID string, permission *model.Permission) bool
+	HasPermissionToChannel(userID, channelID string, permission *model.Permission) bool
 	PublishWebSocketEvent(event string, payload map[string]interface{}, broadcast *model.WebsocketBroadcast)
+	GetConfiguration() *config.Config
+	GetSiteURL() string
 	store.API
 }
 
@@ -54,6 +118,10 @@ type splunk struct {
 	currentUser store.SplunkUser
 
 	httpClient *http.Client
+
+	// ctx is cancelled when the plugin stops, background work is stopped with it
+	ctx    context.Context
+	cancel context.CancelFunc
 }
 
 // New returns new Splunk API object
@@ -83,6 +151,8 @@ type currentUserResponse struct {
 	} `xml:"entry>content>dict>key"`
 }
 
+// authCheck fetches the user context of the current user's token and sets its username.
+// It's never cached, so that logins always check the token on the server.
 func (s *splunk) authCheck() error {
 	resp, err := s.doHTTPRequest(http.MethodGet, "/services/authentication/current-context", nil)
 	if err != nil {
@@ -94,15 +164,17 @@ func (s *splunk) authCheck() error {
 		log.Println(err)
 		return errors.Wrap(err, "authorization")
 	}
+	var username string
 	for _, r := range c.Data {
 		if r.Name == "username" {
-			s.currentUser.UserName = r.Data
+			username = r.Data
 		}
 	}
-
-	if s.currentUser.UserName == "" {
+	if username == "" {
 		return errors.New("authorization")
 	}
+
+	s.currentUser.UserName = username
 	return nil
 }
 
@@ -117,11 +189,29 @@ func (s *splunk) SyncUser(mattermostUserID string) error {
 	return nil
 }
 
+// SyncUserForServer syncs user of the given server stored in KVStore with user stored in memory.
+// If there is no such user, in memory user will only have server set.
+func (s *splunk) SyncUserForServer(mattermostUserID string, server string) error {
+	u, err := s.Store.UserForServer(mattermostUserID, server)
+	if err != nil {
+		s.currentUser = store.SplunkUser{Server: server}
+		return err
+	}
+	s.currentUser = u
+	return nil
+}
+
 // LoginUser changes authorized user.
 // id is either username or username/token of user.
-func (s *splunk) LoginUser(mattermostUserID string, server string, id string) error {
+// serverType is detected from the server URL if it's empty.
+func (s *splunk) LoginUser(mattermostUserID string, server string, serverType string, id string) error {
 	var isNew = true
 
+	server, serverType, err := ServerURL(server, serverType, s.GetConfiguration().ManagementPort)
+	if err != nil {
+		return err
+	}
+
 	// id can be username or username/token
 	username, token, err := extractUserInfo(id)
 	if err != nil {
@@ -130,6 +220,9 @@ func (s *splunk) LoginUser(mattermostUserID string, server string, id string) er
 
 	// check if we already have token for given username
 	if u, err := s.Store.User(mattermostUserID, server, username); err == nil {
+		if u.Type == "" {
+			u.Type = serverType
+		}
 		s.currentUser = u
 		isNew = false
 	} else {
@@ -138,14 +231,20 @@ func (s *splunk) LoginUser(mattermostUserID string, server string, id string) er
 		}
 
 		s.currentUser = store.SplunkUser{
-			Server: server,
-			Token:  token,
+			Server:     server,
+			UserName:   username,
+			Token:      token,
+			Type:       serverType,
+			AuthScheme: tokenAuthScheme(token),
 		}
+		// new credentials may have other permissions than the previous ones of this user
+		s.invalidateMetadata(s.currentUser)
 	}
 
 	if authErr := s.authCheck(); authErr != nil {
+		loginErr := loginError(s.currentUser, authErr)
 		s.currentUser = store.SplunkUser{}
-		return authErr
+		return loginErr
 	}
 
 	if isNew {
@@ -157,6 +256,7 @@ func (s *splunk) LoginUser(mattermostUserID string, server string, id string) er
 
 // LogoutUser logs user out.
 func (s *splunk) LogoutUser(mattermostUserID string) error {
+	s.invalidateMetadata(s.currentUser)
 	_ = s.Store.ChangeCurrentUser(mattermostUserID, "")
 	err := s.Store.DeleteUser(mattermostUserID, s.currentUser.Server, s.currentUser.UserName)
 	s.currentUser = store.SplunkUser{}
@@ -169,10 +269,16 @@ func newSplunk(api PluginAPI, st store.Store) *splunk {
 		Store:      st,
 		httpClient: http.DefaultClient,
 	}
+	s.ctx, s.cancel = context.WithCancel(context.Background())
 
 	return s
 }
 
+// Close stops background work like log exports.
+func (s *splunk) Close() {
+	s.cancel()
+}
+
 func extractUserInfo(id string) (string, string, error) {
 	if id == "" {
 		return "", "", errors.New("Please provide username and token like so: username/token. You can user username only if already authenticated")
@@ -1,8 +1,11 @@
 package splunk
 
 import (
+	"net/http"
+	"net/http/httptest"
 	"testing"
 
+	"github.com/mattermost/mattermost-plugin-splunk/server/config"
 	"github.com/mattermost/mattermost-plugin-splunk/server/store"
 	"github.com/mattermost/mattermost-plugin-splunk/server/store/mock"
 
@@ -50,7 +53,7 @@ func Test_splunk_ChangeUser(t *testing.T) {
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
 			s := New(nil, m)
-			err := s.LoginUser("", tt.args.server, tt.args.id)
+			err := s.LoginUser("", tt.args.server, "", tt.args.id)
 			if tt.wantErr && err == nil {
 				is.Fail("expected error but didn't get any")
 			}
@@ -112,3 +115,25 @@ func Test_splunk_extractUserInfo(t *testing.T) {
 		})
 	}
 }
+
+func Test_splunk_authCheckIsNotCached(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	is := assert.New(t)
+
+	requests := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		_, _ = w.Write([]byte(`<feed><entry><content><dict><key name="username">admin</key></dict></content></entry></feed>`))
+	}))
+	defer server.Close()
+
+	// the store has no expectations, so any cache lookup fails the test
+	s := newSplunk(&fakePluginAPI{config: &config.Config{MetadataCacheTTL: 5}}, mock.NewMockStore(ctrl))
+	for i := 0; i < 2; i++ {
+		s.currentUser = store.SplunkUser{Server: server.URL, Token: "token"}
+		is.NoError(s.authCheck())
+		is.Equal("admin", s.User().UserName)
+	}
+	is.Equal(2, requests)
+}
@@ -0,0 +1,305 @@
+package splunk
+
+import (
+	"context"
+	"encoding/csv"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/mattermost/mattermost-server/v6/model"
+	"github.com/pkg/errors"
+
+	"github.com/mattermost/mattermost-plugin-splunk/server/store"
+)
+
+const (
+	// SearchExportEndpoint endpoint for streaming search results
+	SearchExportEndpoint = "/services/search/jobs/export"
+
+	// maxExportPosts is the number of posts results are split into before they are uploaded as a file
+	maxExportPosts = 5
+	// maxExportRows and maxExportFileSize limit streamed results, the rest is dropped
+	maxExportRows     = 100000
+	maxExportFileSize = 50 * 1024 * 1024
+	// exportPostSize leaves space for the message header in each post
+	exportPostSize = model.PostMessageMaxRunesV2 - 200
+	// exportTimeout limits a single export, it's also how long the export lock of the user is held at most
+	exportTimeout = 10 * time.Minute
+)
+
+// errExportRunning is returned when the user starts an export while their previous one is running.
+var errExportRunning = errors.New("your previous export is still running, wait until it's posted")
+
+// ExportLogs streams all events of the last 24 hours of the log source to the user's direct channel with the bot.
+// The search is started in the background after the log source and the user are checked.
+func (s *splunk) ExportLogs(userID string, channelID string, alias string) error {
+	source, err := s.Store.GetLogSource(channelID, alias)
+	if err != nil {
+		return errors.Wrap(err, "error while getting log source")
+	}
+	if source.Alias == "" {
+		return errors.Errorf("log source %s is not found in this channel", alias)
+	}
+
+	user := s.User()
+	if user.Token == "" {
+		return errors.New("you need to log in to Splunk to export logs")
+	}
+	dm, err := s.GetDirectChannel(userID, s.BotUser())
+	if err != nil {
+		return err
+	}
+
+	if err = s.lockExport(userID); err != nil {
+		return err
+	}
+	go func() {
+		defer s.unlockExport(userID)
+		if exportErr := s.exportSearch(user, dm.Id, source); exportErr != nil {
+			s.LogWarn("failed to export logs", "alias", source.Alias, "error", exportErr.Error())
+			_, _ = s.CreatePost(&model.Post{
+				UserId:    s.BotUser(),
+				ChannelId: dm.Id,
+				Message:   fmt.Sprintf("Failed to export log source %s. %s", source.Alias, exportErr.Error()),
+			})
+		}
+	}()
+	return nil
+}
+
+// lockExport allows one export per user at a time across the cluster.
+// The lock expires with exportTimeout in case it's not released.
+func (s *splunk) lockExport(userID string) error {
+	var locked bool
+	err := s.Store.UpdateEphemeral(exportLockKey(userID), &locked, exportTimeout, func(found bool) error {
+		if found && locked {
+			return errExportRunning
+		}
+		locked = true
+		return nil
+	})
+	if err == errExportRunning {
+		return err
+	}
+	if err != nil {
+		return errors.Wrap(err, "failed to start export")
+	}
+	return nil
+}
+
+func (s *splunk) unlockExport(userID string) {
+	if err := s.Store.DeleteEphemeral(exportLockKey(userID)); err != nil {
+		s.LogWarn("failed to release export lock", "userID", userID, "error", err.Error())
+	}
+}
+
+func exportLockKey(userID string) string {
+	return "export_" + userID
+}
+
+// exportSearch streams results of the log source search and posts them to the channel
+// as markdown tables, or as a CSV file if they don't fit into maxExportPosts posts.
+func (s *splunk) exportSearch(user store.SplunkUser, channelID string, source store.LogSource) error {
+	body := url.Values{
+		"search":        {LogSourceSearch(source)},
+		"output_mode":   {"csv"},
+		"earliest_time": {logEarliest},
+	}
+	ctx, cancel := context.WithTimeout(s.ctx, exportTimeout)
+	defer cancel()
+	resp, err := s.doHTTPRequestWithContext(ctx, user, http.MethodPost, SearchExportEndpoint, strings.NewReader(body.Encode()))
+	if err != nil {
+		return errors.Wrap(err, "no data for search results")
+	}
+	defer func() { _ = resp.Body.Close() }()
+
+	// rows are written to a temporary file, so only the file upload holds the whole CSV in memory
+	file, err := ioutil.TempFile("", "splunk-export-*.csv")
+	if err != nil {
+		return errors.Wrap(err, "failed to create export file")
+	}
+	defer func() {
+		_ = file.Close()
+		_ = os.Remove(file.Name())
+	}()
+
+	w := newExportWriter(file)
+	if err = w.readFrom(resp.Body); err != nil {
+		return errors.Wrap(err, "unexpected response")
+	}
+
+	title := fmt.Sprintf("#### Log source %s\n%d events of the last 24 hours", source.Alias, w.rows)
+	if w.truncated {
+		title = fmt.Sprintf("#### Log source %s\nMore events than can be exported, the first %d events of the last 24 hours are exported", source.Alias, w.rows)
+	}
+
+	if !w.overflow {
+		chunks := w.chunks()
+		if len(chunks) == 0 {
+			chunks = []string{"Log is empty"}
+		}
+		for i, chunk := range chunks {
+			if i == 0 {
+				chunk = title + "\n\n" + chunk
+			}
+			if _, err = s.CreatePost(&model.Post{UserId: s.BotUser(), ChannelId: channelID, Message: chunk}); err != nil {
+				return errors.Wrap(err, "error creating post for exported logs")
+			}
+		}
+		return nil
+	}
+
+	data, err := ioutil.ReadFile(file.Name())
+	if err != nil {
+		return errors.Wrap(err, "failed to read export file")
+	}
+	info, err := s.UploadFile(data, channelID, source.Alias+".csv")
+	if err != nil {
+		return err
+	}
+	_, err = s.CreatePost(&model.Post{
+		UserId:    s.BotUser(),
+		ChannelId: channelID,
+		Message:   title + ", they are attached as CSV.",
+		FileIds:   []string{info.Id},
+	})
+	if err != nil {
+		return errors.Wrap(err, "error creating post for exported logs")
+	}
+	return nil
+}
+
+// exportWriter collects streamed rows as markdown post chunks and writes them as CSV,
+// markdown is dropped once it doesn't fit into maxExportPosts posts.
+// Internal splunk fields except _time and _raw are left out.
+type exportWriter struct {
+	columns []int
+	header  []string
+
+	posts    []string
+	current  strings.Builder
+	overflow bool
+
+	csv       countingWriter
+	csvWriter *csv.Writer
+
+	rows      int
+	truncated bool
+}
+
+func newExportWriter(out io.Writer) *exportWriter {
+	w := &exportWriter{csv: countingWriter{w: out}}
+	w.csvWriter = csv.NewWriter(&w.csv)
+	return w
+}
+
+// countingWriter counts bytes written to w.
+type countingWriter struct {
+	w io.Writer
+	n int64
+}
+
+func (c *countingWriter) Write(p []byte) (int, error) {
+	n, err := c.w.Write(p)
+	c.n += int64(n)
+	return n, err
+}
+
+// readFrom reads CSV results until they end or exceed the export limits.
+func (w *exportWriter) readFrom(r io.Reader) error {
+	reader := csv.NewReader(r)
+	reader.FieldsPerRecord = -1
+	reader.ReuseRecord = true
+
+	record, err := reader.Read()
+	if err == io.EOF {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	for i, name := range record {
+		if !strings.HasPrefix(name, "_") || name == "_time" || name == "_raw" {
+			w.columns = append(w.columns, i)
+			w.header = append(w.header, name)
+		}
+	}
+	if err = w.csvWriter.Write(w.header); err != nil {
+		return err
+	}
+
+	row := make([]string, len(w.columns))
+	for {
+		record, err = reader.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return err
+		}
+		if w.rows >= maxExportRows || w.csv.n >= maxExportFileSize {
+			w.truncated = true
+			break
+		}
+
+		for i, column := range w.columns {
+			row[i] = ""
+			if column < len(record) {
+				row[i] = record[column]
+			}
+		}
+		if err = w.csvWriter.Write(row); err != nil {
+			return err
+		}
+		w.addMarkdownRow(row)
+		w.rows++
+	}
+	w.csvWriter.Flush()
+	return w.csvWriter.Error()
+}
+
+func (w *exportWriter) addMarkdownRow(row []string) {
+	if w.overflow {
+		return
+	}
+
+	line := markdownRow(row)
+	if len(line) > exportPostSize/2 {
+		// rows this long are only readable in the file
+		w.dropMarkdown()
+		return
+	}
+	if w.current.Len() > 0 && w.current.Len()+len(line) > exportPostSize {
+		w.posts = append(w.posts, w.current.String())
+		w.current.Reset()
+	}
+	if w.current.Len() == 0 {
+		if len(w.posts) == maxExportPosts {
+			w.dropMarkdown()
+			return
+		}
+		w.current.WriteString(markdownRow(w.header))
+		w.current.WriteString("|" + strings.Repeat(" :- |", len(w.header)) + "\n")
+	}
+	w.current.WriteString(line)
+}
+
+func (w *exportWriter) dropMarkdown() {
+	w.overflow = true
+	w.posts = nil
+	w.current.Reset()
+}
+
+// chunks returns markdown tables each fitting into a post.
+func (w *exportWriter) chunks() []string {
+	if w.current.Len() == 0 {
+		return w.posts
+	}
+	return append(w.posts, w.current.String())
+}
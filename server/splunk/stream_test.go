@@ -0,0 +1,85 @@
+package splunk
+
+import (
+	"bytes"
+	"fmt"
+	"io/ioutil"
+	"strings"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/mattermost/mattermost-plugin-splunk/server/store/mock"
+)
+
+func Test_exportWriter(t *testing.T) {
+	t.Run("single post", func(t *testing.T) {
+		var out bytes.Buffer
+		w := newExportWriter(&out)
+		err := w.readFrom(strings.NewReader("_bkt,_time,host,_raw\nmain~1,2021-03-10,web-1,GET /\nmain~2,2021-03-10,web-2,GET /a|b\n"))
+		assert.NoError(t, err)
+		assert.Equal(t, 2, w.rows)
+		assert.False(t, w.overflow)
+		assert.Equal(t, []string{"| _time | host | _raw |\n| :- | :- | :- |\n| 2021-03-10 | web-1 | GET / |\n| 2021-03-10 | web-2 | GET /a\\|b |\n"}, w.chunks())
+		assert.Equal(t, "_time,host,_raw\n2021-03-10,web-1,GET /\n2021-03-10,web-2,GET /a|b\n", out.String())
+	})
+
+	t.Run("split into posts", func(t *testing.T) {
+		var sb strings.Builder
+		sb.WriteString("host,_raw\n")
+		for i := 0; i < 300; i++ {
+			sb.WriteString(fmt.Sprintf("web-%d,%s\n", i, strings.Repeat("x", 100)))
+		}
+		w := newExportWriter(ioutil.Discard)
+		assert.NoError(t, w.readFrom(strings.NewReader(sb.String())))
+		assert.False(t, w.overflow)
+		chunks := w.chunks()
+		assert.True(t, len(chunks) > 1)
+		for _, chunk := range chunks {
+			assert.True(t, len(chunk) <= exportPostSize)
+			assert.True(t, strings.HasPrefix(chunk, "| host | _raw |\n"))
+		}
+	})
+
+	t.Run("uploaded as file", func(t *testing.T) {
+		var sb strings.Builder
+		sb.WriteString("host,_raw\n")
+		for i := 0; i < 2000; i++ {
+			sb.WriteString(fmt.Sprintf("web-%d,%s\n", i, strings.Repeat("x", 100)))
+		}
+		var out bytes.Buffer
+		w := newExportWriter(&out)
+		assert.NoError(t, w.readFrom(strings.NewReader(sb.String())))
+		assert.True(t, w.overflow)
+		assert.Empty(t, w.chunks())
+		assert.Equal(t, 2000, w.rows)
+		assert.Equal(t, sb.String(), out.String())
+	})
+
+	t.Run("empty", func(t *testing.T) {
+		w := newExportWriter(ioutil.Discard)
+		assert.NoError(t, w.readFrom(strings.NewReader("")))
+		assert.Empty(t, w.chunks())
+	})
+}
+
+func Test_splunk_lockExport(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	entries := make(map[string][]byte)
+	m := mock.NewMockStore(ctrl)
+	expectEphemeralUpdates(m, entries)
+	m.EXPECT().DeleteEphemeral(exportLockKey("user")).DoAndReturn(func(key string) error {
+		delete(entries, key)
+		return nil
+	})
+
+	s := newSplunk(&fakePluginAPI{}, m)
+	assert.NoError(t, s.lockExport("user"))
+	assert.Equal(t, errExportRunning, s.lockExport("user"), "one export per user at a time")
+	assert.NoError(t, s.lockExport("other"))
+	s.unlockExport("user")
+	assert.NoError(t, s.lockExport("user"))
+}
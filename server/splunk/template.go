@@ -0,0 +1,72 @@
+package splunk
+
+import (
+	"fmt"
+	"strings"
+	"text/template"
+	"unicode/utf8"
+
+	"github.com/mattermost/mattermost-server/v6/model"
+	"github.com/pkg/errors"
+)
+
+// alertTemplateData is passed to the alert subscription message template.
+// Payload fields and methods are available directly, e.g. {{.ResultsLink}} or {{.Severity}}.
+type alertTemplateData struct {
+	AlertActionWHPayload
+	AlertID string
+	// Name is the label of the subscription, empty if it has no name.
+	Name string
+	// Preview is the result preview table, empty if preview is disabled.
+	Preview string
+}
+
+// alertTemplateFuncs returns helper functions available in alert message templates.
+func alertTemplateFuncs(payload AlertActionWHPayload) template.FuncMap {
+	return template.FuncMap{
+		// field returns value of the first result row field
+		"field": payload.Result.Field,
+		// truncate shortens s to at most n characters
+		"truncate": truncate,
+		// link creates markdown link
+		"link": func(text string, url string) string {
+			return fmt.Sprintf("[%s](%s)", strings.ReplaceAll(text, "]", "\\]"), url)
+		},
+	}
+}
+
+// truncate shortens s to at most n characters ending with ... if it's shortened.
+func truncate(n int, s string) string {
+	if utf8.RuneCountInString(s) <= n {
+		return s
+	}
+	if n <= 3 {
+		return string([]rune(s)[:n])
+	}
+	return string([]rune(s)[:n-3]) + "..."
+}
+
+// ParseAlertTemplate checks that text is a valid alert message template.
+func ParseAlertTemplate(text string) error {
+	_, err := template.New("alert").Funcs(alertTemplateFuncs(AlertActionWHPayload{})).Parse(text)
+	return err
+}
+
+// renderAlertTemplate executes the alert message template for the payload.
+func renderAlertTemplate(text string, data alertTemplateData) (string, error) {
+	tmpl, err := template.New("alert").Funcs(alertTemplateFuncs(data.AlertActionWHPayload)).Parse(text)
+	if err != nil {
+		return "", errors.Wrap(err, "invalid template")
+	}
+
+	var sb strings.Builder
+	if err = tmpl.Execute(&sb, data); err != nil {
+		return "", errors.Wrap(err, "error while executing template")
+	}
+
+	message := sb.String()
+	if utf8.RuneCountInString(message) > model.PostMessageMaxRunesV2 {
+		message = string([]rune(message)[:model.PostMessageMaxRunesV2])
+	}
+	return message, nil
+}
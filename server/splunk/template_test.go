@@ -0,0 +1,48 @@
+package splunk
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_renderAlertTemplate(t *testing.T) {
+	var payload AlertActionWHPayload
+	err := json.Unmarshal([]byte(`{
+		"sid": "rt_scheduler__admin",
+		"search_name": "High CPU",
+		"results_link": "http://splunk/results",
+		"result": {"host": "web-1", "severity": "critical", "message": "CPU usage is above the threshold"}
+	}`), &payload)
+	assert.NoError(t, err)
+
+	tests := []struct {
+		name     string
+		template string
+		want     string
+		wantErr  bool
+	}{
+		{
+			name:     "fields and helpers",
+			template: `{{.Severity}}: {{link .SearchName .ResultsLink}} on {{field "host"}} - {{truncate 12 (field "message")}}`,
+			want:     "critical: [High CPU](http://splunk/results) on web-1 - CPU usage...",
+		},
+		{name: "preview", template: `{{.AlertID}}{{if .Preview}} {{.Preview}}{{end}}`, want: "alert table"},
+		{name: "missing field", template: `{{field "missing"}}`, want: ""},
+		{name: "parse error", template: `{{.Sid`, wantErr: true},
+		{name: "execution error", template: `{{.Missing}}`, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := renderAlertTemplate(tt.template, alertTemplateData{AlertActionWHPayload: payload, AlertID: "alert", Preview: "table"})
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
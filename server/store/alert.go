@@ -1,28 +1,81 @@
 package store
 
 import (
+	"encoding/json"
 	"fmt"
+	"strings"
 
 	"github.com/pkg/errors"
 )
 
 const (
-	splunkAlertKey = "splunkalert"
-	splunkAlertMap = "splunkalertmap"
+	// PayloadFormatStandard is the splunk webhook alert action payload, used if subscription has no format
+	PayloadFormatStandard = "standard"
+	// PayloadFormatTokenized is flat JSON of custom alert actions with $result.field$ and other tokens
+	PayloadFormatTokenized = "tokenized"
+	// PayloadFormatRaw posts any request body as it is
+	PayloadFormatRaw = "raw"
+)
+
+const (
+	splunkAlertKey     = "splunkalert"
+	splunkAlertMap     = "splunkalertmap"
+	splunkAlertInfoKey = "splunkalertinfo"
+	// splunkMutedAlertsKey stores IDs of muted subscriptions, so muted ones can be found without listing all of them
+	splunkMutedAlertsKey = "mutedalerts"
 )
 
 // AlertStore API for alert KVStore.
 type AlertStore interface {
 	GetChannelIDForAlert(alert string) (string, error)
+	GetAlert(alertID string) (Alert, error)
+	GetAlerts() ([]Alert, error)
 	GetChannelAlertIDs(channelID string) ([]string, error)
-	CreateAlert(channelID string, alertsID string) error
+	CreateAlert(alert Alert) error
+	UpdateAlert(alert Alert) error
 	DeleteChannelAlert(channelID string, alertsID string) error
+
+	GetMutedAlertIDs() ([]string, bool, error)
+	AddMutedAlertIDs(alertIDs []string) error
+	RemoveMutedAlertID(alertID string) error
+}
+
+// Alert stores splunk alert subscription info.
+type Alert struct {
+	ID        string
+	ChannelID string
+	// Name is optional label of the subscription, unique in the channel.
+	Name string
+	// CreatorID is mattermost ID of the user who created the subscription.
+	CreatorID string
+	// Server and UserName identify splunk user the creator was authorized as.
+	Server   string
+	UserName string
+
+	// SearchName, App and Owner identify saved search which triggered the alert last time.
+	SearchName string
+	App        string
+	Owner      string
+
+	// Template is go text/template used to format alert posts, default format is used if it's empty.
+	Template string
+	// PayloadFormat is the format webhook requests of the subscription are decoded with, standard if it's empty.
+	PayloadFormat string
+	// DisabledReason is why the subscription was disabled, alerts of disabled subscriptions are rejected.
+	DisabledReason string
+
+	// MutedUntil is unix time in milliseconds until which alerts of the subscription are not posted, 0 if it's not muted.
+	MutedUntil int64
 }
 
 func keyWithChannelID(channelID string) string {
 	return fmt.Sprintf("%s_%s", splunkAlertKey, channelID)
 }
 
+func keyWithAlertID(alertID string) string {
+	return fmt.Sprintf("%s_%s", splunkAlertInfoKey, alertID)
+}
+
 func (s *pluginStore) GetChannelIDForAlert(alertID string) (string, error) {
 	var alertsMap map[string]string
 	err := s.alertStore.loadJSON(splunkAlertMap, &alertsMap)
@@ -35,13 +88,65 @@ func (s *pluginStore) GetChannelIDForAlert(alertID string) (string, error) {
 	return "", nil
 }
 
+// GetAlert returns subscription info for the alert
+// returned alert has empty ChannelID if alert is not found.
+func (s *pluginStore) GetAlert(alertID string) (Alert, error) {
+	var alert Alert
+	err := s.alertStore.loadJSON(keyWithAlertID(alertID), &alert)
+	if err != nil {
+		return Alert{}, errors.Wrap(err, "failed to load splunk alert from store")
+	}
+	if alert.ID != "" {
+		return alert, nil
+	}
+
+	// subscriptions created before alert info was stored only have channel mapping
+	channelID, err := s.GetChannelIDForAlert(alertID)
+	if err != nil {
+		return Alert{}, err
+	}
+	return Alert{ID: alertID, ChannelID: channelID}, nil
+}
+
+// GetAlerts returns all alert subscriptions.
+func (s *pluginStore) GetAlerts() ([]Alert, error) {
+	keys, err := s.alertStore.List(splunkAlertInfoKey + "_")
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to list splunk alerts")
+	}
+
+	var alerts []Alert
+	found := make(map[string]bool)
+	for _, key := range keys {
+		alert, loadErr := s.GetAlert(strings.TrimPrefix(key, splunkAlertInfoKey+"_"))
+		if loadErr != nil {
+			return nil, loadErr
+		}
+		alerts = append(alerts, alert)
+		found[alert.ID] = true
+	}
+
+	// subscriptions created before alert info was stored only have channel mapping
+	var alertsMap = make(map[string]string)
+	if err = s.alertStore.loadJSON(splunkAlertMap, &alertsMap); err != nil {
+		return nil, errors.Wrap(err, "failed to load splunk alerts from store")
+	}
+	for alertID, channelID := range alertsMap {
+		if !found[alertID] {
+			alerts = append(alerts, Alert{ID: alertID, ChannelID: channelID})
+		}
+	}
+	return alerts, nil
+}
+
 func (s *pluginStore) GetChannelAlertIDs(channelID string) ([]string, error) {
 	var alerts []string
 	err := s.alertStore.loadJSON(keyWithChannelID(channelID), &alerts)
 	return alerts, err
 }
 
-func (s *pluginStore) CreateAlert(channelID string, alertID string) error {
+func (s *pluginStore) CreateAlert(alert Alert) error {
+	channelID, alertID := alert.ChannelID, alert.ID
 	channelAlerts, err := s.GetChannelAlertIDs(channelID)
 	if err != nil {
 		return errors.Wrapf(err, "failed to get alerts for channel %s", channelID)
@@ -63,6 +168,29 @@ func (s *pluginStore) CreateAlert(channelID string, alertID string) error {
 		return errors.Wrapf(err, "failed to save splunk alerts for channel %s", channelID)
 	}
 
+	err = s.alertStore.setJSON(keyWithAlertID(alertID), alert)
+	if err != nil {
+		return errors.Wrapf(err, "failed to save splunk alert %s", alertID)
+	}
+
+	return nil
+}
+
+// UpdateAlert overwrites info of the existing alert.
+func (s *pluginStore) UpdateAlert(alert Alert) error {
+	channelID, err := s.GetChannelIDForAlert(alert.ID)
+	if err != nil {
+		return err
+	}
+	if channelID == "" {
+		return errors.New("alert to update was not found")
+	}
+
+	alert.ChannelID = channelID
+	err = s.alertStore.setJSON(keyWithAlertID(alert.ID), alert)
+	if err != nil {
+		return errors.Wrapf(err, "failed to save splunk alert %s", alert.ID)
+	}
 	return nil
 }
 
@@ -99,5 +227,62 @@ func (s *pluginStore) DeleteChannelAlert(channelID string, alertID string) error
 		return errors.Wrap(err, "error deleting alert in subscription: error storing subscription in KV store")
 	}
 
+	err = s.alertStore.Delete(keyWithAlertID(alertID))
+	if err != nil {
+		return errors.Wrap(err, "error deleting alert info from KV store")
+	}
+
+	return nil
+}
+
+// GetMutedAlertIDs returns IDs of muted subscriptions, found is false if the index was never stored.
+func (s *pluginStore) GetMutedAlertIDs() ([]string, bool, error) {
+	data, err := s.alertStore.Load(splunkMutedAlertsKey)
+	if err != nil {
+		return nil, false, errors.Wrap(err, "failed to load muted splunk alerts from store")
+	}
+	if data == nil {
+		return nil, false, nil
+	}
+	var ids []string
+	if err = json.Unmarshal(data, &ids); err != nil {
+		return nil, false, errors.Wrap(err, "failed to decode muted splunk alerts")
+	}
+	return ids, true, nil
+}
+
+// AddMutedAlertIDs adds subscriptions to the muted ones, the index is stored even if alertIDs is empty.
+func (s *pluginStore) AddMutedAlertIDs(alertIDs []string) error {
+	var ids []string
+	err := s.alertStore.updateJSON(splunkMutedAlertsKey, &ids, 0, func(found bool) error {
+		if !found {
+			ids = []string{}
+		}
+		for _, alertID := range alertIDs {
+			if findInSlice(ids, alertID) == -1 {
+				ids = append(ids, alertID)
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return errors.Wrap(err, "failed to store muted splunk alerts")
+	}
+	return nil
+}
+
+// RemoveMutedAlertID removes subscription from the muted ones.
+func (s *pluginStore) RemoveMutedAlertID(alertID string) error {
+	var ids []string
+	err := s.alertStore.updateJSON(splunkMutedAlertsKey, &ids, 0, func(found bool) error {
+		if !found {
+			ids = []string{}
+		}
+		ids = deleteFromSlice(ids, findInSlice(ids, alertID))
+		return nil
+	})
+	if err != nil {
+		return errors.Wrap(err, "failed to store muted splunk alerts")
+	}
 	return nil
 }
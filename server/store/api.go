@@ -2,6 +2,9 @@ package store
 
 import (
 	"encoding/json"
+	"reflect"
+	"strings"
+	"time"
 
 	"github.com/mattermost/mattermost-server/v6/model"
 	"github.com/pkg/errors"
@@ -11,7 +14,10 @@ import (
 type API interface {
 	KVGet(key string) ([]byte, *model.AppError)
 	KVSet(key string, value []byte) *model.AppError
+	KVSetWithExpiry(key string, value []byte, expireInSeconds int64) *model.AppError
+	KVSetWithOptions(key string, value []byte, options model.PluginKVSetOptions) (bool, *model.AppError)
 	KVDelete(key string) *model.AppError
+	KVList(page, perPage int) ([]string, *model.AppError)
 	LogDebug(msg string, keyValuePairs ...interface{})
 	LogInfo(msg string, keyValuePairs ...interface{})
 	LogError(msg string, keyValuePairs ...interface{})
@@ -22,11 +28,18 @@ type API interface {
 type KVStore interface {
 	Load(key string) ([]byte, error)
 	Store(key string, data []byte) error
+	StoreWithExpiry(key string, data []byte, ttl time.Duration) error
 	Delete(key string) error
+	List(prefix string) ([]string, error)
 	setJSON(key string, v interface{}) error
+	setJSONWithExpiry(key string, v interface{}, ttl time.Duration) error
 	loadJSON(key string, v interface{}) error
+	updateJSON(key string, v interface{}, ttl time.Duration, update func(found bool) error) error
 }
 
+// maxUpdateAttempts is how many times updateJSON retries when the entry is changed concurrently
+const maxUpdateAttempts = 10
+
 type store struct {
 	api API
 }
@@ -55,6 +68,14 @@ func (s *store) Store(key string, data []byte) error {
 	return nil
 }
 
+func (s *store) StoreWithExpiry(key string, data []byte, ttl time.Duration) error {
+	appErr := s.api.KVSetWithExpiry(key, data, expireInSeconds(ttl))
+	if appErr != nil {
+		return errors.Wrapf(appErr, "Error while storing data with expiry with KVStore with key : %q", key)
+	}
+	return nil
+}
+
 func (s *store) Delete(key string) error {
 	appErr := s.api.KVDelete(key)
 	if appErr != nil {
@@ -63,6 +84,28 @@ func (s *store) Delete(key string) error {
 	return nil
 }
 
+// listPerPage is the page size used to enumerate KVStore keys.
+const listPerPage = 200
+
+// List returns all keys starting with the given prefix.
+func (s *store) List(prefix string) ([]string, error) {
+	var keys []string
+	for page := 0; ; page++ {
+		pageKeys, appErr := s.api.KVList(page, listPerPage)
+		if appErr != nil {
+			return nil, errors.Wrapf(appErr, "Error while listing keys from KVStore on page : %d", page)
+		}
+		for _, key := range pageKeys {
+			if strings.HasPrefix(key, prefix) {
+				keys = append(keys, key)
+			}
+		}
+		if len(pageKeys) < listPerPage {
+			return keys, nil
+		}
+	}
+}
+
 func (s *store) loadJSON(key string, v interface{}) (returnErr error) {
 	bytes, err := s.Load(key)
 	if err != nil {
@@ -81,3 +124,72 @@ func (s *store) setJSON(key string, v interface{}) error {
 	}
 	return s.Store(key, bytes)
 }
+
+func (s *store) setJSONWithExpiry(key string, v interface{}, ttl time.Duration) error {
+	bytes, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+	return s.StoreWithExpiry(key, bytes, ttl)
+}
+
+// compareAndSet stores data under the key only if its current value is old, nil old means the key doesn't exist.
+// Zero ttl stores data without expiry. Returns false if the value was changed in the meantime.
+func (s *store) compareAndSet(key string, old []byte, data []byte, ttl time.Duration) (bool, error) {
+	ok, appErr := s.api.KVSetWithOptions(key, data, model.PluginKVSetOptions{
+		Atomic:          true,
+		OldValue:        old,
+		ExpireInSeconds: expireInSeconds(ttl),
+	})
+	if appErr != nil {
+		return false, errors.Wrapf(appErr, "Error while updating data with KVStore with key : %q", key)
+	}
+	return ok, nil
+}
+
+// updateJSON atomically updates JSON value of the key: it's loaded into v, changed by update and stored for ttl,
+// zero ttl stores it without expiry. found tells update whether the key existed, v is left as is otherwise.
+// If the value is changed concurrently, e.g. by another node of the cluster, update runs again on the new value.
+func (s *store) updateJSON(key string, v interface{}, ttl time.Duration, update func(found bool) error) error {
+	for attempt := 0; attempt < maxUpdateAttempts; attempt++ {
+		old, err := s.Load(key)
+		if err != nil {
+			return err
+		}
+		if old != nil {
+			// json.Unmarshal merges into maps and structs, the value of the previous attempt must not be kept
+			reset(v)
+			if err = json.Unmarshal(old, v); err != nil {
+				return errors.Wrapf(err, "failed to decode value of key %q", key)
+			}
+		}
+		if err = update(old != nil); err != nil {
+			return err
+		}
+
+		data, err := json.Marshal(v)
+		if err != nil {
+			return err
+		}
+		ok, err := s.compareAndSet(key, old, data, ttl)
+		if err != nil {
+			return err
+		}
+		if ok {
+			return nil
+		}
+	}
+	return errors.Errorf("key %q was changed concurrently too many times", key)
+}
+
+// reset sets the value v points to to its zero value.
+func reset(v interface{}) {
+	if rv := reflect.ValueOf(v); rv.Kind() == reflect.Ptr && !rv.IsNil() {
+		rv.Elem().Set(reflect.Zero(rv.Elem().Type()))
+	}
+}
+
+// expireInSeconds converts ttl to the expiry of KVStore, rounded up so that short TTLs don't become no expiry.
+func expireInSeconds(ttl time.Duration) int64 {
+	return int64((ttl + time.Second - 1) / time.Second)
+}
@@ -0,0 +1,16 @@
+package store
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_expireInSeconds(t *testing.T) {
+	assert.Equal(t, int64(0), expireInSeconds(0))
+	assert.Equal(t, int64(1), expireInSeconds(time.Millisecond))
+	assert.Equal(t, int64(1), expireInSeconds(time.Second))
+	assert.Equal(t, int64(2), expireInSeconds(1500*time.Millisecond))
+	assert.Equal(t, int64(600), expireInSeconds(10*time.Minute))
+}
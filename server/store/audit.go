@@ -0,0 +1,62 @@
+package store
+
+import (
+	"github.com/pkg/errors"
+)
+
+const (
+	auditLogKey = "auditlog"
+	// maxAuditEntries is the number of the latest entries kept, older ones are dropped
+	maxAuditEntries = 1000
+)
+
+// AuditStore API for audit log KVStore.
+type AuditStore interface {
+	AppendAuditEntry(entry AuditEntry) error
+	GetAuditEntries() ([]AuditEntry, error)
+}
+
+// AuditEntry records a sensitive action made with the plugin.
+// JSON field names are the ones of the events forwarded to splunk.
+type AuditEntry struct {
+	ID string `json:"id"`
+	// Time is unix time of the action in milliseconds.
+	Time int64 `json:"time"`
+	// ActorID is mattermost ID of the user who made the action, empty for actions of the plugin itself.
+	ActorID   string `json:"actor_id,omitempty"`
+	Action    string `json:"action"`
+	ChannelID string `json:"channel_id,omitempty"`
+	// Details describe the action, e.g. command arguments with secrets left out.
+	Details string `json:"details,omitempty"`
+	// Result is the outcome of the action.
+	Result string `json:"result,omitempty"`
+}
+
+// AppendAuditEntry adds entry to the audit log, only the latest maxAuditEntries are kept.
+// The log is updated atomically, so entries appended at the same time by other nodes are not lost.
+func (s *pluginStore) AppendAuditEntry(entry AuditEntry) error {
+	var entries []AuditEntry
+	err := s.auditStore.updateJSON(auditLogKey, &entries, 0, func(found bool) error {
+		if !found {
+			entries = nil
+		}
+		entries = append(entries, entry)
+		if len(entries) > maxAuditEntries {
+			entries = entries[len(entries)-maxAuditEntries:]
+		}
+		return nil
+	})
+	if err != nil {
+		return errors.Wrap(err, "failed to store audit entry")
+	}
+	return nil
+}
+
+// GetAuditEntries returns audit log entries, oldest first.
+func (s *pluginStore) GetAuditEntries() ([]AuditEntry, error) {
+	var entries []AuditEntry
+	if err := s.auditStore.loadJSON(auditLogKey, &entries); err != nil {
+		return nil, errors.Wrap(err, "failed to load audit log")
+	}
+	return entries, nil
+}
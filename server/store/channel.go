@@ -0,0 +1,70 @@
+package store
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+const channelServerKeyPrefix = "channelserver_"
+
+// ChannelStore API for channel KVStore.
+type ChannelStore interface {
+	GetChannelServer(channelID string) (string, error)
+	GetChannelServers() (map[string]string, error)
+	SetChannelServer(channelID string, server string) error
+	DeleteChannelServer(channelID string) error
+}
+
+func keyWithChannelServerPrefix(channelID string) string {
+	return fmt.Sprintf("%s%s", channelServerKeyPrefix, channelID)
+}
+
+// GetChannelServer returns splunk server bound to the channel
+// or empty string if channel has no server.
+func (s *pluginStore) GetChannelServer(channelID string) (string, error) {
+	var server string
+	err := s.channelStore.loadJSON(keyWithChannelServerPrefix(channelID), &server)
+	if err != nil {
+		return "", errors.Wrapf(err, "failed to load server for channel %s", channelID)
+	}
+	return server, nil
+}
+
+// GetChannelServers returns splunk servers of all channels with a server by channel ID.
+func (s *pluginStore) GetChannelServers() (map[string]string, error) {
+	keys, err := s.channelStore.List(channelServerKeyPrefix)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to list channel servers")
+	}
+
+	servers := make(map[string]string, len(keys))
+	for _, key := range keys {
+		channelID := strings.TrimPrefix(key, channelServerKeyPrefix)
+		server, err := s.GetChannelServer(channelID)
+		if err != nil {
+			return nil, err
+		}
+		servers[channelID] = server
+	}
+	return servers, nil
+}
+
+// SetChannelServer binds splunk server to the channel.
+func (s *pluginStore) SetChannelServer(channelID string, server string) error {
+	err := s.channelStore.setJSON(keyWithChannelServerPrefix(channelID), server)
+	if err != nil {
+		return errors.Wrapf(err, "failed to save server for channel %s", channelID)
+	}
+	return nil
+}
+
+// DeleteChannelServer removes splunk server binding from the channel.
+func (s *pluginStore) DeleteChannelServer(channelID string) error {
+	err := s.channelStore.Delete(keyWithChannelServerPrefix(channelID))
+	if err != nil {
+		return errors.Wrapf(err, "failed to delete server for channel %s", channelID)
+	}
+	return nil
+}
@@ -0,0 +1,71 @@
+package store
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+const ephemeralKeyPrefix = "ephemeral_"
+
+// EphemeralStore API for short-lived KVStore entries.
+// Entries are removed by the KVStore itself once their TTL passes.
+type EphemeralStore interface {
+	StoreEphemeral(key string, v interface{}, ttl time.Duration) error
+	LoadEphemeral(key string, v interface{}) (bool, error)
+	UpdateEphemeral(key string, v interface{}, ttl time.Duration, update func(found bool) error) error
+	DeleteEphemeral(key string) error
+}
+
+func keyWithEphemeralPrefix(key string) string {
+	return fmt.Sprintf("%s%s", ephemeralKeyPrefix, key)
+}
+
+// StoreEphemeral stores v under the given key for ttl.
+func (s *pluginStore) StoreEphemeral(key string, v interface{}, ttl time.Duration) error {
+	if ttl <= 0 {
+		return errors.New("ttl must be positive")
+	}
+	err := s.ephemeralStore.setJSONWithExpiry(keyWithEphemeralPrefix(key), v, ttl)
+	if err != nil {
+		return errors.Wrapf(err, "failed to store ephemeral entry %s", key)
+	}
+	return nil
+}
+
+// LoadEphemeral loads entry stored under the given key into v.
+// Returns false if the entry doesn't exist or is already expired.
+func (s *pluginStore) LoadEphemeral(key string, v interface{}) (bool, error) {
+	data, err := s.ephemeralStore.Load(keyWithEphemeralPrefix(key))
+	if err != nil {
+		return false, errors.Wrapf(err, "failed to load ephemeral entry %s", key)
+	}
+	if data == nil {
+		return false, nil
+	}
+	if err = json.Unmarshal(data, v); err != nil {
+		return false, errors.Wrapf(err, "failed to decode ephemeral entry %s", key)
+	}
+	return true, nil
+}
+
+// UpdateEphemeral atomically updates entry stored under the given key: the entry is loaded into v,
+// changed by update and stored for ttl. found tells update whether the entry exists,
+// update is called again with the current entry if it's changed concurrently.
+func (s *pluginStore) UpdateEphemeral(key string, v interface{}, ttl time.Duration, update func(found bool) error) error {
+	if ttl <= 0 {
+		return errors.New("ttl must be positive")
+	}
+	err := s.ephemeralStore.updateJSON(keyWithEphemeralPrefix(key), v, ttl, update)
+	if err != nil {
+		return errors.Wrapf(err, "failed to update ephemeral entry %s", key)
+	}
+	return nil
+}
+
+// DeleteEphemeral removes entry stored under the given key.
+func (s *pluginStore) DeleteEphemeral(key string) error {
+	return s.ephemeralStore.Delete(keyWithEphemeralPrefix(key))
+}
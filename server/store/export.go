@@ -0,0 +1,272 @@
+package store
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// BundleVersion is the version of exported plugin data format.
+const BundleVersion = 1
+
+// ExportStore API for exporting and importing all plugin data.
+type ExportStore interface {
+	Export(includeTokens bool) (Bundle, error)
+	Import(bundle Bundle) (ImportSummary, error)
+}
+
+// Bundle stores all plugin data for migrating it to another workspace.
+type Bundle struct {
+	Version        int
+	Users          []UserBundle
+	Alerts         []Alert
+	ChannelServers map[string]string
+	Reports        []ReportSchedule
+	LogSources     []LogSource
+
+	// ChannelNames and UserNames map mattermost IDs referenced in the bundle
+	// to team/channel names and usernames, so that they can be resolved on a workspace with different IDs.
+	ChannelNames map[string]string
+	UserNames    map[string]string
+}
+
+// UserBundle stores all splunk users of a mattermost user.
+type UserBundle struct {
+	MattermostUserID  string
+	LastLoginUserName string
+	SplunkUsers       []SplunkUser
+}
+
+// Export collects all plugin data
+// user tokens are left empty unless includeTokens is set.
+func (s *pluginStore) Export(includeTokens bool) (Bundle, error) {
+	bundle := Bundle{
+		Version:        BundleVersion,
+		ChannelServers: make(map[string]string),
+	}
+
+	userKeys, err := s.userStore.List(UserStoreKeyPrefix)
+	if err != nil {
+		return Bundle{}, errors.Wrap(err, "failed to list users")
+	}
+	for _, key := range userKeys {
+		mattermostUserID := strings.TrimPrefix(key, UserStoreKeyPrefix)
+		u, loadErr := s.loadUser(mattermostUserID)
+		if loadErr != nil {
+			return Bundle{}, loadErr
+		}
+		ub := UserBundle{
+			MattermostUserID:  mattermostUserID,
+			LastLoginUserName: u.LastLoginUserName,
+		}
+		for _, su := range u.SplunkUsers {
+			if !includeTokens {
+				su.Token = ""
+			}
+			ub.SplunkUsers = append(ub.SplunkUsers, su)
+		}
+		bundle.Users = append(bundle.Users, ub)
+	}
+
+	if bundle.Alerts, err = s.GetAlerts(); err != nil {
+		return Bundle{}, err
+	}
+
+	channelKeys, err := s.channelStore.List(channelServerKeyPrefix)
+	if err != nil {
+		return Bundle{}, errors.Wrap(err, "failed to list channel servers")
+	}
+	for _, key := range channelKeys {
+		channelID := strings.TrimPrefix(key, channelServerKeyPrefix)
+		server, loadErr := s.GetChannelServer(channelID)
+		if loadErr != nil {
+			return Bundle{}, loadErr
+		}
+		bundle.ChannelServers[channelID] = server
+	}
+
+	if bundle.Reports, err = s.GetReportSchedules(); err != nil {
+		return Bundle{}, err
+	}
+
+	logSourceKeys, err := s.logSourceStore.List(logSourceKeyPrefix)
+	if err != nil {
+		return Bundle{}, errors.Wrap(err, "failed to list log sources")
+	}
+	for _, key := range logSourceKeys {
+		sources, loadErr := s.GetLogSources(strings.TrimPrefix(key, logSourceKeyPrefix))
+		if loadErr != nil {
+			return Bundle{}, loadErr
+		}
+		bundle.LogSources = append(bundle.LogSources, sources...)
+	}
+	return bundle, nil
+}
+
+// ImportSummary describes what the import couldn't store as it was in the bundle.
+type ImportSummary struct {
+	// Skipped lists entries of the bundle which weren't imported and why.
+	Skipped []string `json:"skipped"`
+	// Mismatches lists alert subscriptions whose stored index disagreed with their records, the import fixed them.
+	Mismatches []string `json:"mismatches"`
+}
+
+// Import stores all data from the bundle
+// existing data is kept, entries with the same IDs are overwritten.
+// Splunk users without tokens are skipped as they can't be used to authenticate.
+// Entries which fail to be stored are skipped and listed in the summary.
+func (s *pluginStore) Import(bundle Bundle) (ImportSummary, error) {
+	var summary ImportSummary
+	if bundle.Version != BundleVersion {
+		return summary, errors.Errorf("unsupported bundle version %d", bundle.Version)
+	}
+	skip := func(entry string, err error) {
+		summary.Skipped = append(summary.Skipped, entry+": "+err.Error())
+	}
+
+	for _, ub := range bundle.Users {
+		for _, su := range ub.SplunkUsers {
+			if su.Token == "" {
+				continue
+			}
+			if err := s.RegisterUser(ub.MattermostUserID, su); err != nil {
+				skip(fmt.Sprintf("user %s of %s", su.UserName, ub.MattermostUserID), err)
+			}
+		}
+		if ub.LastLoginUserName != "" {
+			// fails if the last login user had no token, user will have to login again then
+			_ = s.ChangeCurrentUser(ub.MattermostUserID, ub.LastLoginUserName)
+		}
+	}
+
+	var imported []Alert
+	for _, alert := range bundle.Alerts {
+		mismatches, err := s.importAlert(alert)
+		summary.Mismatches = append(summary.Mismatches, mismatches...)
+		if err != nil {
+			skip("alert "+alert.ID, err)
+			continue
+		}
+		imported = append(imported, alert)
+	}
+	if err := s.indexImportedMutedAlerts(imported); err != nil {
+		return summary, err
+	}
+
+	for channelID, server := range bundle.ChannelServers {
+		if err := s.SetChannelServer(channelID, server); err != nil {
+			skip("server of channel "+channelID, err)
+		}
+	}
+
+	for _, schedule := range bundle.Reports {
+		if err := s.SaveReportSchedule(schedule); err != nil {
+			skip("report "+schedule.ID, err)
+		}
+	}
+
+	for _, source := range bundle.LogSources {
+		if err := s.SaveLogSource(source); err != nil {
+			skip(fmt.Sprintf("log source %s of channel %s", source.Alias, source.ChannelID), err)
+		}
+	}
+	return summary, nil
+}
+
+// importAlert stores the alert on its own record and moves it from the channel it had before.
+// The channel the record has is trusted over the alert map, the map and channel lists
+// are fixed up where they disagree with it and the mismatches are returned.
+func (s *pluginStore) importAlert(alert Alert) ([]string, error) {
+	var existing Alert
+	if err := s.alertStore.loadJSON(keyWithAlertID(alert.ID), &existing); err != nil {
+		return nil, errors.Wrap(err, "failed to load splunk alert from store")
+	}
+	mappedChannelID, err := s.GetChannelIDForAlert(alert.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	var mismatches []string
+	if existing.ID != "" && mappedChannelID != existing.ChannelID {
+		mismatches = append(mismatches, fmt.Sprintf("alert %s has channel %q but is mapped to channel %q", alert.ID, existing.ChannelID, mappedChannelID))
+	}
+	oldChannelIDs := []string{existing.ChannelID}
+	if mappedChannelID != existing.ChannelID {
+		oldChannelIDs = append(oldChannelIDs, mappedChannelID)
+	}
+	for _, channelID := range oldChannelIDs {
+		if channelID == "" || channelID == alert.ChannelID {
+			continue
+		}
+		var removed bool
+		if removed, err = s.updateChannelAlertIDs(channelID, func(ids []string) []string {
+			return deleteFromSlice(ids, findInSlice(ids, alert.ID))
+		}); err != nil {
+			return mismatches, err
+		}
+		if !removed {
+			mismatches = append(mismatches, fmt.Sprintf("alert %s was not listed in channel %s", alert.ID, channelID))
+		}
+	}
+
+	var alertsMap map[string]string
+	err = s.alertStore.updateJSON(splunkAlertMap, &alertsMap, 0, func(found bool) error {
+		if !found || alertsMap == nil {
+			alertsMap = make(map[string]string)
+		}
+		alertsMap[alert.ID] = alert.ChannelID
+		return nil
+	})
+	if err != nil {
+		return mismatches, errors.Wrap(err, "failed to save splunk alerts in store")
+	}
+	if _, err = s.updateChannelAlertIDs(alert.ChannelID, func(ids []string) []string {
+		if findInSlice(ids, alert.ID) == -1 {
+			ids = append(ids, alert.ID)
+		}
+		return ids
+	}); err != nil {
+		return mismatches, err
+	}
+	if err = s.alertStore.setJSON(keyWithAlertID(alert.ID), alert); err != nil {
+		return mismatches, errors.Wrapf(err, "failed to save splunk alert %s", alert.ID)
+	}
+	return mismatches, nil
+}
+
+// updateChannelAlertIDs atomically changes alert IDs of the channel with update,
+// changed is false if update returned the IDs unchanged.
+func (s *pluginStore) updateChannelAlertIDs(channelID string, update func(ids []string) []string) (bool, error) {
+	var ids []string
+	var changed bool
+	err := s.alertStore.updateJSON(keyWithChannelID(channelID), &ids, 0, func(found bool) error {
+		before := len(ids)
+		ids = update(ids)
+		changed = len(ids) != before
+		return nil
+	})
+	if err != nil {
+		return false, errors.Wrapf(err, "failed to save splunk alerts for channel %s", channelID)
+	}
+	return changed, nil
+}
+
+// indexImportedMutedAlerts adds muted alerts to the index of muted ones.
+// If there is no index yet, it's built from all alerts later, so it's not created here.
+func (s *pluginStore) indexImportedMutedAlerts(alerts []Alert) error {
+	_, found, err := s.GetMutedAlertIDs()
+	if err != nil || !found {
+		return err
+	}
+	var ids []string
+	for _, alert := range alerts {
+		if alert.MutedUntil != 0 {
+			ids = append(ids, alert.ID)
+		}
+	}
+	if len(ids) == 0 {
+		return nil
+	}
+	return s.AddMutedAlertIDs(ids)
+}
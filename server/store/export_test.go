@@ -0,0 +1,108 @@
+package store
+
+import (
+	"bytes"
+	"encoding/json"
+	"testing"
+
+	"github.com/mattermost/mattermost-server/v6/model"
+	"github.com/stretchr/testify/assert"
+)
+
+// memoryAPI is KVStore of a single node kept in memory.
+type memoryAPI struct {
+	kv map[string][]byte
+}
+
+func (m *memoryAPI) KVGet(key string) ([]byte, *model.AppError) { return m.kv[key], nil }
+
+func (m *memoryAPI) KVSet(key string, value []byte) *model.AppError {
+	m.kv[key] = value
+	return nil
+}
+
+func (m *memoryAPI) KVSetWithExpiry(key string, value []byte, _ int64) *model.AppError {
+	return m.KVSet(key, value)
+}
+
+func (m *memoryAPI) KVSetWithOptions(key string, value []byte, options model.PluginKVSetOptions) (bool, *model.AppError) {
+	if options.Atomic && !bytes.Equal(m.kv[key], options.OldValue) {
+		return false, nil
+	}
+	m.kv[key] = value
+	return true, nil
+}
+
+func (m *memoryAPI) KVDelete(key string) *model.AppError {
+	delete(m.kv, key)
+	return nil
+}
+
+func (m *memoryAPI) KVList(_, _ int) ([]string, *model.AppError) {
+	var keys []string
+	for key := range m.kv {
+		keys = append(keys, key)
+	}
+	return keys, nil
+}
+
+func (m *memoryAPI) LogDebug(string, ...interface{}) {}
+func (m *memoryAPI) LogInfo(string, ...interface{})  {}
+func (m *memoryAPI) LogError(string, ...interface{}) {}
+func (m *memoryAPI) LogWarn(string, ...interface{})  {}
+
+func (m *memoryAPI) set(t *testing.T, key string, v interface{}) {
+	data, err := json.Marshal(v)
+	assert.NoError(t, err)
+	m.kv[key] = data
+}
+
+func Test_pluginStore_ImportFixesAlertIndex(t *testing.T) {
+	is := assert.New(t)
+	api := &memoryAPI{kv: make(map[string][]byte)}
+	api.set(t, keyWithAlertID("a1"), Alert{ID: "a1", ChannelID: "c1"})
+	api.set(t, keyWithChannelID("c1"), []string{"a1"})
+	api.set(t, splunkAlertMap, map[string]string{"a1": "c2"})
+	s := NewPluginStore(api)
+
+	summary, err := s.Import(Bundle{
+		Version: BundleVersion,
+		Alerts:  []Alert{{ID: "a1", ChannelID: "c3", Name: "errors"}},
+	})
+	is.NoError(err)
+	is.Empty(summary.Skipped)
+	is.Equal([]string{
+		`alert a1 has channel "c1" but is mapped to channel "c2"`,
+		"alert a1 was not listed in channel c2",
+	}, summary.Mismatches)
+
+	alert, err := s.GetAlert("a1")
+	is.NoError(err)
+	is.Equal(Alert{ID: "a1", ChannelID: "c3", Name: "errors"}, alert)
+	channelID, err := s.GetChannelIDForAlert("a1")
+	is.NoError(err)
+	is.Equal("c3", channelID)
+	for channelID, want := range map[string][]string{"c1": {}, "c2": nil, "c3": {"a1"}} {
+		ids, loadErr := s.GetChannelAlertIDs(channelID)
+		is.NoError(loadErr)
+		is.Equal(want, ids, channelID)
+	}
+}
+
+func Test_pluginStore_ImportSkipsFailedEntries(t *testing.T) {
+	api := &memoryAPI{kv: map[string][]byte{splunkAlertMap: []byte("not json")}}
+	s := NewPluginStore(api)
+
+	summary, err := s.Import(Bundle{
+		Version:        BundleVersion,
+		Alerts:         []Alert{{ID: "a1", ChannelID: "c1"}},
+		ChannelServers: map[string]string{"c1": "https://splunk"},
+	})
+	assert.NoError(t, err)
+	assert.Len(t, summary.Skipped, 1)
+	assert.Contains(t, summary.Skipped[0], "alert a1: ")
+
+	server, err := s.GetChannelServer("c1")
+	assert.NoError(t, err)
+	assert.Equal(t, "https://splunk", server, "entries after the failed one are imported")
+}
@@ -0,0 +1,110 @@
+package store
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+const logSourceKeyPrefix = "logsources_"
+
+// LogSourceStore API for log source KVStore.
+type LogSourceStore interface {
+	GetLogSources(channelID string) ([]LogSource, error)
+	GetLogSource(channelID string, alias string) (LogSource, error)
+	SaveLogSource(source LogSource) error
+	DeleteLogSource(channelID string, alias string) error
+}
+
+// LogSource stores named log search shared by users of the channel.
+// Either Index with optional SourceType or SPL is set.
+type LogSource struct {
+	Alias      string
+	ChannelID  string
+	CreatorID  string
+	Index      string
+	SourceType string
+	SPL        string
+}
+
+func keyWithLogSourcePrefix(channelID string) string {
+	return fmt.Sprintf("%s%s", logSourceKeyPrefix, channelID)
+}
+
+func (s *pluginStore) loadLogSources(channelID string) (map[string]LogSource, error) {
+	var sources = make(map[string]LogSource)
+	err := s.logSourceStore.loadJSON(keyWithLogSourcePrefix(channelID), &sources)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to load log sources for channel %s", channelID)
+	}
+	return sources, nil
+}
+
+// GetLogSources returns log sources of the channel sorted by alias
+func (s *pluginStore) GetLogSources(channelID string) ([]LogSource, error) {
+	sources, err := s.loadLogSources(channelID)
+	if err != nil {
+		return nil, err
+	}
+
+	var res []LogSource
+	for _, source := range sources {
+		res = append(res, source)
+	}
+	sort.Slice(res, func(i, j int) bool { return res[i].Alias < res[j].Alias })
+	return res, nil
+}
+
+// GetLogSource returns log source of the channel with the given alias
+// returned log source has empty Alias if it's not found.
+func (s *pluginStore) GetLogSource(channelID string, alias string) (LogSource, error) {
+	sources, err := s.loadLogSources(channelID)
+	if err != nil {
+		return LogSource{}, err
+	}
+	return sources[strings.ToLower(alias)], nil
+}
+
+// SaveLogSource creates or replaces log source with the same alias in the channel
+func (s *pluginStore) SaveLogSource(source LogSource) error {
+	err := s.updateLogSources(source.ChannelID, func(sources map[string]LogSource) error {
+		sources[strings.ToLower(source.Alias)] = source
+		return nil
+	})
+	if err != nil {
+		return errors.Wrapf(err, "failed to save log source %s", source.Alias)
+	}
+	return nil
+}
+
+// DeleteLogSource removes log source from the channel
+func (s *pluginStore) DeleteLogSource(channelID string, alias string) error {
+	errNotFound := errors.New("log source to delete was not found")
+	err := s.updateLogSources(channelID, func(sources map[string]LogSource) error {
+		if _, ok := sources[strings.ToLower(alias)]; !ok {
+			return errNotFound
+		}
+		delete(sources, strings.ToLower(alias))
+		return nil
+	})
+	if err == errNotFound {
+		return err
+	}
+	if err != nil {
+		return errors.Wrapf(err, "failed to delete log source %s", alias)
+	}
+	return nil
+}
+
+// updateLogSources atomically changes the stored log sources of the channel with update.
+func (s *pluginStore) updateLogSources(channelID string, update func(sources map[string]LogSource) error) error {
+	var sources map[string]LogSource
+	return s.logSourceStore.updateJSON(keyWithLogSourcePrefix(channelID), &sources, 0, func(found bool) error {
+		if !found || sources == nil {
+			sources = make(map[string]LogSource)
+		}
+		return update(sources)
+	})
+}
@@ -5,9 +5,11 @@
 package mock
 
 import (
+	reflect "reflect"
+	time "time"
+
 	gomock "github.com/golang/mock/gomock"
 	store "github.com/mattermost/mattermost-plugin-splunk/server/store"
-	reflect "reflect"
 )
 
 // MockStore is a mock of Store interface
@@ -33,6 +35,34 @@ func (m *MockStore) EXPECT() *MockStoreMockRecorder {
 	return m.recorder
 }
 
+// AddMutedAlertIDs mocks base method
+func (m *MockStore) AddMutedAlertIDs(arg0 []string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "AddMutedAlertIDs", arg0)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// AddMutedAlertIDs indicates an expected call of AddMutedAlertIDs
+func (mr *MockStoreMockRecorder) AddMutedAlertIDs(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AddMutedAlertIDs", reflect.TypeOf((*MockStore)(nil).AddMutedAlertIDs), arg0)
+}
+
+// AppendAuditEntry mocks base method
+func (m *MockStore) AppendAuditEntry(arg0 store.AuditEntry) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "AppendAuditEntry", arg0)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// AppendAuditEntry indicates an expected call of AppendAuditEntry
+func (mr *MockStoreMockRecorder) AppendAuditEntry(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AppendAuditEntry", reflect.TypeOf((*MockStore)(nil).AppendAuditEntry), arg0)
+}
+
 // ChangeCurrentUser mocks base method
 func (m *MockStore) ChangeCurrentUser(arg0, arg1 string) error {
 	m.ctrl.T.Helper()
@@ -48,17 +78,17 @@ func (mr *MockStoreMockRecorder) ChangeCurrentUser(arg0, arg1 interface{}) *gomo
 }
 
 // CreateAlert mocks base method
-func (m *MockStore) CreateAlert(arg0, arg1 string) error {
+func (m *MockStore) CreateAlert(arg0 store.Alert) error {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "CreateAlert", arg0, arg1)
+	ret := m.ctrl.Call(m, "CreateAlert", arg0)
 	ret0, _ := ret[0].(error)
 	return ret0
 }
 
 // CreateAlert indicates an expected call of CreateAlert
-func (mr *MockStoreMockRecorder) CreateAlert(arg0, arg1 interface{}) *gomock.Call {
+func (mr *MockStoreMockRecorder) CreateAlert(arg0 interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateAlert", reflect.TypeOf((*MockStore)(nil).CreateAlert), arg0, arg1)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateAlert", reflect.TypeOf((*MockStore)(nil).CreateAlert), arg0)
 }
 
 // CurrentUser mocks base method
@@ -90,6 +120,62 @@ func (mr *MockStoreMockRecorder) DeleteChannelAlert(arg0, arg1 interface{}) *gom
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteChannelAlert", reflect.TypeOf((*MockStore)(nil).DeleteChannelAlert), arg0, arg1)
 }
 
+// DeleteChannelServer mocks base method
+func (m *MockStore) DeleteChannelServer(arg0 string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteChannelServer", arg0)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteChannelServer indicates an expected call of DeleteChannelServer
+func (mr *MockStoreMockRecorder) DeleteChannelServer(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteChannelServer", reflect.TypeOf((*MockStore)(nil).DeleteChannelServer), arg0)
+}
+
+// DeleteEphemeral mocks base method
+func (m *MockStore) DeleteEphemeral(arg0 string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteEphemeral", arg0)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteEphemeral indicates an expected call of DeleteEphemeral
+func (mr *MockStoreMockRecorder) DeleteEphemeral(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteEphemeral", reflect.TypeOf((*MockStore)(nil).DeleteEphemeral), arg0)
+}
+
+// DeleteLogSource mocks base method
+func (m *MockStore) DeleteLogSource(arg0, arg1 string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteLogSource", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteLogSource indicates an expected call of DeleteLogSource
+func (mr *MockStoreMockRecorder) DeleteLogSource(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteLogSource", reflect.TypeOf((*MockStore)(nil).DeleteLogSource), arg0, arg1)
+}
+
+// DeleteReportSchedule mocks base method
+func (m *MockStore) DeleteReportSchedule(arg0 string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteReportSchedule", arg0)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteReportSchedule indicates an expected call of DeleteReportSchedule
+func (mr *MockStoreMockRecorder) DeleteReportSchedule(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteReportSchedule", reflect.TypeOf((*MockStore)(nil).DeleteReportSchedule), arg0)
+}
+
 // DeleteUser mocks base method
 func (m *MockStore) DeleteUser(arg0, arg1, arg2 string) error {
 	m.ctrl.T.Helper()
@@ -104,6 +190,80 @@ func (mr *MockStoreMockRecorder) DeleteUser(arg0, arg1, arg2 interface{}) *gomoc
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteUser", reflect.TypeOf((*MockStore)(nil).DeleteUser), arg0, arg1, arg2)
 }
 
+// DeleteUserData mocks base method
+func (m *MockStore) DeleteUserData(arg0 string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteUserData", arg0)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteUserData indicates an expected call of DeleteUserData
+func (mr *MockStoreMockRecorder) DeleteUserData(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteUserData", reflect.TypeOf((*MockStore)(nil).DeleteUserData), arg0)
+}
+
+// Export mocks base method
+func (m *MockStore) Export(arg0 bool) (store.Bundle, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Export", arg0)
+	ret0, _ := ret[0].(store.Bundle)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Export indicates an expected call of Export
+func (mr *MockStoreMockRecorder) Export(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Export", reflect.TypeOf((*MockStore)(nil).Export), arg0)
+}
+
+// GetAlert mocks base method
+func (m *MockStore) GetAlert(arg0 string) (store.Alert, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetAlert", arg0)
+	ret0, _ := ret[0].(store.Alert)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetAlert indicates an expected call of GetAlert
+func (mr *MockStoreMockRecorder) GetAlert(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetAlert", reflect.TypeOf((*MockStore)(nil).GetAlert), arg0)
+}
+
+// GetAlerts mocks base method
+func (m *MockStore) GetAlerts() ([]store.Alert, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetAlerts")
+	ret0, _ := ret[0].([]store.Alert)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetAlerts indicates an expected call of GetAlerts
+func (mr *MockStoreMockRecorder) GetAlerts() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetAlerts", reflect.TypeOf((*MockStore)(nil).GetAlerts))
+}
+
+// GetAuditEntries mocks base method
+func (m *MockStore) GetAuditEntries() ([]store.AuditEntry, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetAuditEntries")
+	ret0, _ := ret[0].([]store.AuditEntry)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetAuditEntries indicates an expected call of GetAuditEntries
+func (mr *MockStoreMockRecorder) GetAuditEntries() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetAuditEntries", reflect.TypeOf((*MockStore)(nil).GetAuditEntries))
+}
+
 // GetChannelAlertIDs mocks base method
 func (m *MockStore) GetChannelAlertIDs(arg0 string) ([]string, error) {
 	m.ctrl.T.Helper()
@@ -134,6 +294,185 @@ func (mr *MockStoreMockRecorder) GetChannelIDForAlert(arg0 interface{}) *gomock.
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetChannelIDForAlert", reflect.TypeOf((*MockStore)(nil).GetChannelIDForAlert), arg0)
 }
 
+// GetChannelServer mocks base method
+func (m *MockStore) GetChannelServer(arg0 string) (string, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetChannelServer", arg0)
+	ret0, _ := ret[0].(string)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetChannelServer indicates an expected call of GetChannelServer
+func (mr *MockStoreMockRecorder) GetChannelServer(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetChannelServer", reflect.TypeOf((*MockStore)(nil).GetChannelServer), arg0)
+}
+
+// GetChannelServers mocks base method
+func (m *MockStore) GetChannelServers() (map[string]string, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetChannelServers")
+	ret0, _ := ret[0].(map[string]string)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetChannelServers indicates an expected call of GetChannelServers
+func (mr *MockStoreMockRecorder) GetChannelServers() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetChannelServers", reflect.TypeOf((*MockStore)(nil).GetChannelServers))
+}
+
+// GetLogSource mocks base method
+func (m *MockStore) GetLogSource(arg0, arg1 string) (store.LogSource, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetLogSource", arg0, arg1)
+	ret0, _ := ret[0].(store.LogSource)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetLogSource indicates an expected call of GetLogSource
+func (mr *MockStoreMockRecorder) GetLogSource(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetLogSource", reflect.TypeOf((*MockStore)(nil).GetLogSource), arg0, arg1)
+}
+
+// GetLogSources mocks base method
+func (m *MockStore) GetLogSources(arg0 string) ([]store.LogSource, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetLogSources", arg0)
+	ret0, _ := ret[0].([]store.LogSource)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetLogSources indicates an expected call of GetLogSources
+func (mr *MockStoreMockRecorder) GetLogSources(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetLogSources", reflect.TypeOf((*MockStore)(nil).GetLogSources), arg0)
+}
+
+// GetMutedAlertIDs mocks base method
+func (m *MockStore) GetMutedAlertIDs() ([]string, bool, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetMutedAlertIDs")
+	ret0, _ := ret[0].([]string)
+	ret1, _ := ret[1].(bool)
+	ret2, _ := ret[2].(error)
+	return ret0, ret1, ret2
+}
+
+// GetMutedAlertIDs indicates an expected call of GetMutedAlertIDs
+func (mr *MockStoreMockRecorder) GetMutedAlertIDs() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetMutedAlertIDs", reflect.TypeOf((*MockStore)(nil).GetMutedAlertIDs))
+}
+
+// GetReportSchedules mocks base method
+func (m *MockStore) GetReportSchedules() ([]store.ReportSchedule, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetReportSchedules")
+	ret0, _ := ret[0].([]store.ReportSchedule)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetReportSchedules indicates an expected call of GetReportSchedules
+func (mr *MockStoreMockRecorder) GetReportSchedules() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetReportSchedules", reflect.TypeOf((*MockStore)(nil).GetReportSchedules))
+}
+
+// GetUserIDs mocks base method
+func (m *MockStore) GetUserIDs() ([]string, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetUserIDs")
+	ret0, _ := ret[0].([]string)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetUserIDs indicates an expected call of GetUserIDs
+func (mr *MockStoreMockRecorder) GetUserIDs() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetUserIDs", reflect.TypeOf((*MockStore)(nil).GetUserIDs))
+}
+
+// Import mocks base method
+func (m *MockStore) Import(arg0 store.Bundle) (store.ImportSummary, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Import", arg0)
+	ret0, _ := ret[0].(store.ImportSummary)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Import indicates an expected call of Import
+func (mr *MockStoreMockRecorder) Import(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Import", reflect.TypeOf((*MockStore)(nil).Import), arg0)
+}
+
+// IsOnboarded mocks base method
+func (m *MockStore) IsOnboarded(arg0 string) (bool, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "IsOnboarded", arg0)
+	ret0, _ := ret[0].(bool)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// IsOnboarded indicates an expected call of IsOnboarded
+func (mr *MockStoreMockRecorder) IsOnboarded(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "IsOnboarded", reflect.TypeOf((*MockStore)(nil).IsOnboarded), arg0)
+}
+
+// LoadEphemeral mocks base method
+func (m *MockStore) LoadEphemeral(arg0 string, arg1 interface{}) (bool, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "LoadEphemeral", arg0, arg1)
+	ret0, _ := ret[0].(bool)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// LoadEphemeral indicates an expected call of LoadEphemeral
+func (mr *MockStoreMockRecorder) LoadEphemeral(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "LoadEphemeral", reflect.TypeOf((*MockStore)(nil).LoadEphemeral), arg0, arg1)
+}
+
+// MigrateReportSchedules mocks base method
+func (m *MockStore) MigrateReportSchedules(arg0 func(store.ReportSchedule) store.ReportSchedule) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "MigrateReportSchedules", arg0)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// MigrateReportSchedules indicates an expected call of MigrateReportSchedules
+func (mr *MockStoreMockRecorder) MigrateReportSchedules(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "MigrateReportSchedules", reflect.TypeOf((*MockStore)(nil).MigrateReportSchedules), arg0)
+}
+
+// MigrateUsers mocks base method
+func (m *MockStore) MigrateUsers(arg0 string, arg1 func(store.SplunkUser) store.SplunkUser) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "MigrateUsers", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// MigrateUsers indicates an expected call of MigrateUsers
+func (mr *MockStoreMockRecorder) MigrateUsers(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "MigrateUsers", reflect.TypeOf((*MockStore)(nil).MigrateUsers), arg0, arg1)
+}
+
 // RegisterUser mocks base method
 func (m *MockStore) RegisterUser(arg0 string, arg1 store.SplunkUser) error {
 	m.ctrl.T.Helper()
@@ -148,6 +487,132 @@ func (mr *MockStoreMockRecorder) RegisterUser(arg0, arg1 interface{}) *gomock.Ca
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RegisterUser", reflect.TypeOf((*MockStore)(nil).RegisterUser), arg0, arg1)
 }
 
+// RemoveMutedAlertID mocks base method
+func (m *MockStore) RemoveMutedAlertID(arg0 string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RemoveMutedAlertID", arg0)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// RemoveMutedAlertID indicates an expected call of RemoveMutedAlertID
+func (mr *MockStoreMockRecorder) RemoveMutedAlertID(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RemoveMutedAlertID", reflect.TypeOf((*MockStore)(nil).RemoveMutedAlertID), arg0)
+}
+
+// SaveLogSource mocks base method
+func (m *MockStore) SaveLogSource(arg0 store.LogSource) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SaveLogSource", arg0)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SaveLogSource indicates an expected call of SaveLogSource
+func (mr *MockStoreMockRecorder) SaveLogSource(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SaveLogSource", reflect.TypeOf((*MockStore)(nil).SaveLogSource), arg0)
+}
+
+// SaveReportSchedule mocks base method
+func (m *MockStore) SaveReportSchedule(arg0 store.ReportSchedule) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SaveReportSchedule", arg0)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SaveReportSchedule indicates an expected call of SaveReportSchedule
+func (mr *MockStoreMockRecorder) SaveReportSchedule(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SaveReportSchedule", reflect.TypeOf((*MockStore)(nil).SaveReportSchedule), arg0)
+}
+
+// SetChannelServer mocks base method
+func (m *MockStore) SetChannelServer(arg0, arg1 string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SetChannelServer", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SetChannelServer indicates an expected call of SetChannelServer
+func (mr *MockStoreMockRecorder) SetChannelServer(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetChannelServer", reflect.TypeOf((*MockStore)(nil).SetChannelServer), arg0, arg1)
+}
+
+// SetOnboarded mocks base method
+func (m *MockStore) SetOnboarded(arg0 string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SetOnboarded", arg0)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SetOnboarded indicates an expected call of SetOnboarded
+func (mr *MockStoreMockRecorder) SetOnboarded(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetOnboarded", reflect.TypeOf((*MockStore)(nil).SetOnboarded), arg0)
+}
+
+// SetReportNextRun mocks base method
+func (m *MockStore) SetReportNextRun(arg0 string, arg1 time.Time) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SetReportNextRun", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SetReportNextRun indicates an expected call of SetReportNextRun
+func (mr *MockStoreMockRecorder) SetReportNextRun(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetReportNextRun", reflect.TypeOf((*MockStore)(nil).SetReportNextRun), arg0, arg1)
+}
+
+// StoreEphemeral mocks base method
+func (m *MockStore) StoreEphemeral(arg0 string, arg1 interface{}, arg2 time.Duration) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "StoreEphemeral", arg0, arg1, arg2)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// StoreEphemeral indicates an expected call of StoreEphemeral
+func (mr *MockStoreMockRecorder) StoreEphemeral(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "StoreEphemeral", reflect.TypeOf((*MockStore)(nil).StoreEphemeral), arg0, arg1, arg2)
+}
+
+// UpdateAlert mocks base method
+func (m *MockStore) UpdateAlert(arg0 store.Alert) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateAlert", arg0)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UpdateAlert indicates an expected call of UpdateAlert
+func (mr *MockStoreMockRecorder) UpdateAlert(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateAlert", reflect.TypeOf((*MockStore)(nil).UpdateAlert), arg0)
+}
+
+// UpdateEphemeral mocks base method
+func (m *MockStore) UpdateEphemeral(arg0 string, arg1 interface{}, arg2 time.Duration, arg3 func(bool) error) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateEphemeral", arg0, arg1, arg2, arg3)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UpdateEphemeral indicates an expected call of UpdateEphemeral
+func (mr *MockStoreMockRecorder) UpdateEphemeral(arg0, arg1, arg2, arg3 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateEphemeral", reflect.TypeOf((*MockStore)(nil).UpdateEphemeral), arg0, arg1, arg2, arg3)
+}
+
 // User mocks base method
 func (m *MockStore) User(arg0, arg1, arg2 string) (store.SplunkUser, error) {
 	m.ctrl.T.Helper()
@@ -162,3 +627,18 @@ func (mr *MockStoreMockRecorder) User(arg0, arg1, arg2 interface{}) *gomock.Call
 	mr.mock.ctrl.T.Helper()
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "User", reflect.TypeOf((*MockStore)(nil).User), arg0, arg1, arg2)
 }
+
+// UserForServer mocks base method
+func (m *MockStore) UserForServer(arg0, arg1 string) (store.SplunkUser, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UserForServer", arg0, arg1)
+	ret0, _ := ret[0].(store.SplunkUser)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// UserForServer indicates an expected call of UserForServer
+func (mr *MockStoreMockRecorder) UserForServer(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UserForServer", reflect.TypeOf((*MockStore)(nil).UserForServer), arg0, arg1)
+}
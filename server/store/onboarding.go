@@ -0,0 +1,38 @@
+package store
+
+import (
+	"fmt"
+
+	"github.com/pkg/errors"
+)
+
+const onboardingKeyPrefix = "onboarded_"
+
+// OnboardingStore API for onboarding KVStore.
+type OnboardingStore interface {
+	IsOnboarded(mattermostUserID string) (bool, error)
+	SetOnboarded(mattermostUserID string) error
+}
+
+func keyWithOnboardingPrefix(mattermostUserID string) string {
+	return fmt.Sprintf("%s%s", onboardingKeyPrefix, mattermostUserID)
+}
+
+// IsOnboarded checks if the user already received the getting started message
+func (s *pluginStore) IsOnboarded(mattermostUserID string) (bool, error) {
+	var onboarded bool
+	err := s.onboardingStore.loadJSON(keyWithOnboardingPrefix(mattermostUserID), &onboarded)
+	if err != nil {
+		return false, errors.Wrapf(err, "failed to load onboarding state for user %s", mattermostUserID)
+	}
+	return onboarded, nil
+}
+
+// SetOnboarded marks the user as having received the getting started message
+func (s *pluginStore) SetOnboarded(mattermostUserID string) error {
+	err := s.onboardingStore.setJSON(keyWithOnboardingPrefix(mattermostUserID), true)
+	if err != nil {
+		return errors.Wrapf(err, "failed to save onboarding state for user %s", mattermostUserID)
+	}
+	return nil
+}
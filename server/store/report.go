@@ -0,0 +1,141 @@
+package store
+
+import (
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+const splunkReportsKey = "splunkreports"
+
+const (
+	// ReportDaily report is delivered every day.
+	ReportDaily = "daily"
+	// ReportWeekly report is delivered once a week.
+	ReportWeekly = "weekly"
+)
+
+// ReportStore API for scheduled reports KVStore.
+type ReportStore interface {
+	GetReportSchedules() ([]ReportSchedule, error)
+	SaveReportSchedule(schedule ReportSchedule) error
+	SetReportNextRun(scheduleID string, nextRun time.Time) error
+	MigrateReportSchedules(migrate func(ReportSchedule) ReportSchedule) error
+	DeleteReportSchedule(scheduleID string) error
+}
+
+// ReportSchedule stores scheduled delivery of splunk saved report to the channel.
+type ReportSchedule struct {
+	ID        string
+	ChannelID string
+	// CreatorID is mattermost ID of the user who scheduled the report.
+	CreatorID string
+	// Server and UserName identify splunk user the report is run as.
+	Server   string
+	UserName string
+
+	Report string
+	// Interval is either ReportDaily or ReportWeekly.
+	Interval string
+	Weekday  time.Weekday
+	Hour     int
+	Minute   int
+	// Location is time zone name in which delivery time is specified.
+	Location string
+	NextRun  time.Time
+}
+
+// GetReportSchedules returns all scheduled reports.
+func (s *pluginStore) GetReportSchedules() ([]ReportSchedule, error) {
+	schedules, err := s.loadReportSchedules()
+	if err != nil {
+		return nil, err
+	}
+
+	res := make([]ReportSchedule, 0, len(schedules))
+	for _, schedule := range schedules {
+		res = append(res, schedule)
+	}
+	return res, nil
+}
+
+// SaveReportSchedule creates new report schedule or overwrites existing one with the same ID.
+func (s *pluginStore) SaveReportSchedule(schedule ReportSchedule) error {
+	err := s.updateReportSchedules(func(schedules map[string]ReportSchedule) error {
+		schedules[schedule.ID] = schedule
+		return nil
+	})
+	if err != nil {
+		return errors.Wrap(err, "failed to save splunk reports in store")
+	}
+	return nil
+}
+
+// SetReportNextRun changes only the next delivery time of the report schedule.
+// Schedules deleted in the meantime are not stored again.
+func (s *pluginStore) SetReportNextRun(scheduleID string, nextRun time.Time) error {
+	err := s.updateReportSchedules(func(schedules map[string]ReportSchedule) error {
+		if schedule, ok := schedules[scheduleID]; ok {
+			schedule.NextRun = nextRun
+			schedules[scheduleID] = schedule
+		}
+		return nil
+	})
+	if err != nil {
+		return errors.Wrap(err, "failed to save splunk reports in store")
+	}
+	return nil
+}
+
+// MigrateReportSchedules atomically replaces all schedules with the ones returned by migrate.
+func (s *pluginStore) MigrateReportSchedules(migrate func(ReportSchedule) ReportSchedule) error {
+	err := s.updateReportSchedules(func(schedules map[string]ReportSchedule) error {
+		for id, schedule := range schedules {
+			schedules[id] = migrate(schedule)
+		}
+		return nil
+	})
+	if err != nil {
+		return errors.Wrap(err, "failed to save splunk reports in store")
+	}
+	return nil
+}
+
+// DeleteReportSchedule removes report schedule.
+func (s *pluginStore) DeleteReportSchedule(scheduleID string) error {
+	errNotFound := errors.New("report to delete was not found")
+	err := s.updateReportSchedules(func(schedules map[string]ReportSchedule) error {
+		if _, ok := schedules[scheduleID]; !ok {
+			return errNotFound
+		}
+		delete(schedules, scheduleID)
+		return nil
+	})
+	if err == errNotFound {
+		return err
+	}
+	if err != nil {
+		return errors.Wrap(err, "error deleting report: error storing reports in KV store")
+	}
+	return nil
+}
+
+// updateReportSchedules atomically changes the stored schedules with update.
+func (s *pluginStore) updateReportSchedules(update func(schedules map[string]ReportSchedule) error) error {
+	var schedules map[string]ReportSchedule
+	return s.reportStore.updateJSON(splunkReportsKey, &schedules, 0, func(found bool) error {
+		if !found || schedules == nil {
+			schedules = make(map[string]ReportSchedule)
+		}
+		return update(schedules)
+	})
+}
+
+func (s *pluginStore) loadReportSchedules() (map[string]ReportSchedule, error) {
+	var schedules = make(map[string]ReportSchedule)
+	err := s.reportStore.loadJSON(splunkReportsKey, &schedules)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to load splunk reports from store")
+	}
+	return schedules, nil
+}
@@ -4,17 +4,36 @@ package store
 type Store interface {
 	UserStore
 	AlertStore
+	ChannelStore
+	EphemeralStore
+	ReportStore
+	ExportStore
+	OnboardingStore
+	LogSourceStore
+	AuditStore
 }
 
 type pluginStore struct {
-	userStore  KVStore
-	alertStore KVStore
+	userStore       KVStore
+	alertStore      KVStore
+	channelStore    KVStore
+	ephemeralStore  KVStore
+	reportStore     KVStore
+	onboardingStore KVStore
+	logSourceStore  KVStore
+	auditStore      KVStore
 }
 
 // NewPluginStore creates Store object from plugin.API
 func NewPluginStore(api API) Store {
 	return &pluginStore{
-		alertStore: NewStore(api),
-		userStore:  NewStore(api),
+		alertStore:      NewStore(api),
+		userStore:       NewStore(api),
+		channelStore:    NewStore(api),
+		ephemeralStore:  NewStore(api),
+		reportStore:     NewStore(api),
+		onboardingStore: NewStore(api),
+		logSourceStore:  NewStore(api),
+		auditStore:      NewStore(api),
 	}
 }
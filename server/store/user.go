@@ -2,6 +2,7 @@ package store
 
 import (
 	"fmt"
+	"strings"
 
 	"github.com/pkg/errors"
 )
@@ -9,14 +10,26 @@ import (
 // UserStoreKeyPrefix prefix for user data key is KVStore.
 const UserStoreKeyPrefix = "user_"
 
+const (
+	// ServerTypeEnterprise is self-hosted Splunk Enterprise server
+	ServerTypeEnterprise = "enterprise"
+	// ServerTypeCloud is Splunk Cloud deployment
+	ServerTypeCloud = "cloud"
+)
+
 // UserStore API for user KVStore.
 type UserStore interface {
 	CurrentUser(mattermostUserID string) (SplunkUser, error)
 	User(mattermostUserID string, server string, username string) (SplunkUser, error)
+	UserForServer(mattermostUserID string, server string) (SplunkUser, error)
 
 	ChangeCurrentUser(mattermostUserID string, userName string) error
 	RegisterUser(mattermostUserID string, user SplunkUser) error
 	DeleteUser(mattermostUserID string, server string, userName string) error
+	MigrateUsers(mattermostUserID string, migrate func(SplunkUser) SplunkUser) error
+
+	GetUserIDs() ([]string, error)
+	DeleteUserData(mattermostUserID string) error
 }
 
 // SplunkUser stores splunk user info.
@@ -24,6 +37,11 @@ type SplunkUser struct {
 	Server   string
 	UserName string
 	Token    string
+	// Type is either ServerTypeEnterprise or ServerTypeCloud, empty for users stored before types were added.
+	Type string
+	// AuthScheme is the scheme of the Authorization header used with Token,
+	// empty for users stored before it was detected from the token.
+	AuthScheme string
 }
 
 // user KVStore value for each user
@@ -62,6 +80,29 @@ func (s *pluginStore) User(mattermostUserID string, server string, username stri
 	return SplunkUser{}, errors.New("no user found")
 }
 
+// UserForServer returns user info for given server
+// last authorized user is preferred if there are several users for the server.
+func (s *pluginStore) UserForServer(mattermostUserID string, server string) (SplunkUser, error) {
+	su, err := s.loadUser(mattermostUserID)
+	if err != nil {
+		return SplunkUser{}, err
+	}
+
+	var res *SplunkUser
+	for i, u := range su.SplunkUsers {
+		if u.Server != server {
+			continue
+		}
+		if res == nil || u.UserName == su.LastLoginUserName {
+			res = &su.SplunkUsers[i]
+		}
+	}
+	if res == nil {
+		return SplunkUser{}, errors.New("no user found")
+	}
+	return *res, nil
+}
+
 // ChangeCurrentUser changes authorized user to given one
 // if userName is empty string it's equivalent of logout.
 func (s *pluginStore) ChangeCurrentUser(mattermostUserID string, userName string) error {
@@ -133,6 +174,49 @@ func (s *pluginStore) DeleteUser(mattermostUserID string, server string, userNam
 	return s.storeUser(mattermostUserID, su)
 }
 
+// MigrateUsers replaces splunk users of the mattermost user with the ones returned by migrate.
+// Nothing is stored if no user is changed.
+func (s *pluginStore) MigrateUsers(mattermostUserID string, migrate func(SplunkUser) SplunkUser) error {
+	su, err := s.loadUser(mattermostUserID)
+	if err != nil {
+		return err
+	}
+
+	changed := false
+	for i, u := range su.SplunkUsers {
+		if migrated := migrate(u); migrated != u {
+			su.SplunkUsers[i] = migrated
+			changed = true
+		}
+	}
+	if !changed {
+		return nil
+	}
+	return s.storeUser(mattermostUserID, su)
+}
+
+// GetUserIDs returns IDs of mattermost users who have stored splunk users
+func (s *pluginStore) GetUserIDs() ([]string, error) {
+	keys, err := s.userStore.List(UserStoreKeyPrefix)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to list users")
+	}
+	var ids []string
+	for _, key := range keys {
+		ids = append(ids, strings.TrimPrefix(key, UserStoreKeyPrefix))
+	}
+	return ids, nil
+}
+
+// DeleteUserData deletes all splunk users and tokens of the mattermost user
+func (s *pluginStore) DeleteUserData(mattermostUserID string) error {
+	err := s.userStore.Delete(fmt.Sprintf("%s%s", UserStoreKeyPrefix, mattermostUserID))
+	if err != nil {
+		return errors.Wrapf(err, "error while deleting a user with id : %s", mattermostUserID)
+	}
+	return nil
+}
+
 func (s *pluginStore) loadUser(mattermostUserID string) (*user, error) {
 	u := &user{}
 	err := s.userStore.loadJSON(fmt.Sprintf("%s%s", UserStoreKeyPrefix, mattermostUserID), u)